@@ -1,21 +1,71 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/charlesaraya/pokedex-go/internal/cache"
 	"github.com/charlesaraya/pokedex-go/internal/commands"
 	"github.com/charlesaraya/pokedex-go/internal/pokedex"
+	"github.com/charlesaraya/pokedex-go/internal/render"
 	"github.com/charlesaraya/pokedex-go/internal/terminal"
 )
 
+const (
+	RUN_SCRIPT string = "run"
+)
+
+// Exit codes of the Pokédex
+const (
+	EXIT_FAILURE int = 1 // a command of a script failed
+	EXIT_USAGE   int = 2 // wrong flags or arguments, or an unknown command in a script
+)
+
 func main() {
+	output := flag.String("output", render.FORMAT_TEXT, "format of the results of commands: text, json, csv or yaml")
+	script := flag.String("c", "", "commands to run instead of the interactive prompt, separated by ;")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [%s <script>]\n", os.Args[0], RUN_SCRIPT)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	registry := commands.NewRegistry()
+	if err := registry.SetOutput(*output); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(EXIT_USAGE)
+	}
+
 	var duration, _ = time.ParseDuration("5s")
 	var cache = cache.NewCache(duration)
 	cache.Pokedex = pokedex.NewPokedex()
 
+	// run a script and exit, when not started from a terminal or given one
+	switch {
+	case *script != "":
+		os.Exit(runScript(strings.NewReader(*script), registry, cache))
+	case flag.NArg() > 0:
+		if flag.Arg(0) != RUN_SCRIPT || flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(EXIT_USAGE)
+		}
+		file, err := os.Open(flag.Arg(1))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error: failed to open script:", err)
+			os.Exit(EXIT_USAGE)
+		}
+		code := runScript(file, registry, cache)
+		file.Close()
+		os.Exit(code)
+	case !terminal.IsTerminal(os.Stdin):
+		os.Exit(runScript(terminal.Stdin, registry, cache))
+	}
+
+	registry.SetInteractive(true)
 	err := terminal.EnableRawMode()
 	if err != nil {
 		fmt.Println("Error: failed to terminal enable raw mode", err)
@@ -26,7 +76,6 @@ func main() {
 	commandHistory, historyIdx := terminal.InitCommandHistory()
 	inputBuffer, cursor := terminal.InitBuffer()
 	buf := make([]byte, 3)
-	registry := commands.GetRegistry()
 
 	for {
 		terminal.RedrawLine(inputBuffer, cursor)
@@ -63,21 +112,28 @@ func main() {
 			terminal.DeleteFromBuffer(&inputBuffer, &cursor)
 		case terminal.KEY_PRINTABLE:
 			terminal.AddToBuffer(rune(buf[0]), &inputBuffer, &cursor)
+		case terminal.KEY_TAB:
+			words := terminal.CleanInput(string(inputBuffer[:cursor]))
+			if cursor == 0 || inputBuffer[cursor-1] == ' ' {
+				words = append(words, "")
+			}
+			matches := terminal.CompleteWord(&inputBuffer, &cursor, registry.Completions(words, cache))
+			if len(matches) > 1 {
+				fmt.Println()
+				terminal.PrettyPrint(matches)
+			}
 		case terminal.KEY_ENTER:
 			if len(inputBuffer) > 0 {
 				fmt.Println() // move to next line
-				// Check if the user entered a valid command
-				fullCommand := terminal.CleanInput(string(inputBuffer))
-				Cmd, ok := registry[fullCommand[0]]
-				if !ok {
-					fmt.Printf("Error: unknown command %q\n", fullCommand[0])
-				} else {
-					if len(fullCommand) > 1 {
-						Cmd.Config.Params = fullCommand[1:]
-					}
-					if err := Cmd.Command(Cmd.Config, cache); err != nil {
-						fmt.Printf("Error: %s command produced an error: %s\n", Cmd.Name, err)
-					}
+				err := registry.Execute(string(inputBuffer), cache)
+				if errors.Is(err, commands.ErrExit) {
+					return
+				}
+				// a command that failed already told why
+				if err != nil && !errors.Is(err, commands.ErrFailed) {
+					fmt.Println("Error:", err)
+				}
+				if !errors.Is(err, commands.ErrUnknownCommand) {
 					terminal.AddCommand(string(inputBuffer), &commandHistory, &historyIdx)
 				}
 			}
@@ -86,3 +142,16 @@ func main() {
 		}
 	}
 }
+
+// runScript runs the commands of a script and returns the exit code of the Pokédex
+func runScript(r io.Reader, registry *commands.Registry, c *cache.Cache) int {
+	err := registry.RunScript(r, c)
+	if err == nil {
+		return 0
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	if errors.Is(err, commands.ErrUnknownCommand) || errors.Is(err, commands.ErrUsage) {
+		return EXIT_USAGE
+	}
+	return EXIT_FAILURE
+}
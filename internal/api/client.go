@@ -13,6 +13,15 @@ const (
 	ENDPOINT_POKEMON       string = "https://pokeapi.co/api/v2/pokemon/"
 	ENDPOINT_LOCATION_AREA string = "https://pokeapi.co/api/v2/location-area/"
 	ENDPOINT_LOCATION      string = "https://pokeapi.co/api/v2/location/"
+	ENDPOINT_REGION        string = "https://pokeapi.co/api/v2/region/"
+	ENDPOINT_SPECIES       string = "https://pokeapi.co/api/v2/pokemon-species/"
+	ENDPOINT_MOVE          string = "https://pokeapi.co/api/v2/move/"
+	ENDPOINT_POKEDEX       string = "https://pokeapi.co/api/v2/pokedex/"
+	ENDPOINT_GENERATION    string = "https://pokeapi.co/api/v2/generation/"
+	ENDPOINT_GROWTH_RATE   string = "https://pokeapi.co/api/v2/growth-rate/"
+	ENDPOINT_NATURE        string = "https://pokeapi.co/api/v2/nature/"
+	ENDPOINT_EVOLUTION     string = "https://pokeapi.co/api/v2/evolution-chain/"
+	NATIONAL_POKEDEX       string = "national"
 	PAGINATION             string = "?offset=0&limit=20"
 )
 
@@ -41,9 +50,189 @@ type Location struct {
 	Region NamedResource   `json:"region"`
 }
 
+type Region struct {
+	Name      string          `json:"name"`
+	Locations []NamedResource `json:"locations"`
+}
+
+type NamedResources struct {
+	Count    int             `json:"count"`
+	Next     string          `json:"next"`
+	Previous string          `json:"previous"`
+	Results  []NamedResource `json:"results"`
+}
+
+type PokemonSpecies struct {
+	Name        string        `json:"name"`
+	CaptureRate int           `json:"capture_rate"`
+	GenderRate  int           `json:"gender_rate"`
+	GrowthRate  NamedResource `json:"growth_rate"`
+	// friendship of a freshly caught Pokémon
+	BaseHappiness  int `json:"base_happiness"`
+	EvolutionChain struct {
+		URL string `json:"url"`
+	} `json:"evolution_chain"`
+}
+
+// EvolutionDetail holds the conditions of an evolution. Conditions the game doesn't
+// know about are kept as pointers, nil when the evolution doesn't need them.
+type EvolutionDetail struct {
+	Trigger               NamedResource  `json:"trigger"`
+	Item                  NamedResource  `json:"item"`
+	MinLevel              int            `json:"min_level"`
+	MinHappiness          int            `json:"min_happiness"`
+	TimeOfDay             string         `json:"time_of_day"`
+	Gender                int            `json:"gender"`
+	HeldItem              *NamedResource `json:"held_item"`
+	KnownMove             *NamedResource `json:"known_move"`
+	KnownMoveType         *NamedResource `json:"known_move_type"`
+	Location              *NamedResource `json:"location"`
+	PartySpecies          *NamedResource `json:"party_species"`
+	PartyType             *NamedResource `json:"party_type"`
+	TradeSpecies          *NamedResource `json:"trade_species"`
+	MinAffection          *int           `json:"min_affection"`
+	MinBeauty             *int           `json:"min_beauty"`
+	RelativePhysicalStats *int           `json:"relative_physical_stats"`
+	NeedsOverworldRain    bool           `json:"needs_overworld_rain"`
+	TurnUpsideDown        bool           `json:"turn_upside_down"`
+}
+
+// Supported reports whether the game knows about every condition of an evolution
+func (d EvolutionDetail) Supported() bool {
+	return d.HeldItem == nil && d.KnownMove == nil && d.KnownMoveType == nil && d.Location == nil &&
+		d.PartySpecies == nil && d.PartyType == nil && d.TradeSpecies == nil && d.MinAffection == nil &&
+		d.MinBeauty == nil && d.RelativePhysicalStats == nil && !d.NeedsOverworldRain && !d.TurnUpsideDown
+}
+
+type ChainLink struct {
+	Species          NamedResource     `json:"species"`
+	EvolutionDetails []EvolutionDetail `json:"evolution_details"`
+	EvolvesTo        []ChainLink       `json:"evolves_to"`
+}
+
+type EvolutionChain struct {
+	ID    int       `json:"id"`
+	Chain ChainLink `json:"chain"`
+}
+
+// Evolutions returns the supported ways a species of the chain evolves
+func (e EvolutionChain) Evolutions(species string) []pokedex.Evolution {
+	var find func(link ChainLink) []pokedex.Evolution
+	find = func(link ChainLink) []pokedex.Evolution {
+		if link.Species.Name != species {
+			for _, next := range link.EvolvesTo {
+				if evolutions := find(next); evolutions != nil {
+					return evolutions
+				}
+			}
+			return nil
+		}
+		evolutions := []pokedex.Evolution{}
+		for _, next := range link.EvolvesTo {
+			for _, detail := range next.EvolutionDetails {
+				if !detail.Supported() {
+					continue
+				}
+				evolution := pokedex.Evolution{
+					Species:       next.Species.Name,
+					Trigger:       detail.Trigger.Name,
+					MinLevel:      detail.MinLevel,
+					MinFriendship: detail.MinHappiness,
+					TimeOfDay:     detail.TimeOfDay,
+					Item:          detail.Item.Name,
+				}
+				switch detail.Gender {
+				case 1:
+					evolution.Gender = pokedex.GENDER_FEMALE
+				case 2:
+					evolution.Gender = pokedex.GENDER_MALE
+				}
+				evolutions = append(evolutions, evolution)
+			}
+		}
+		return evolutions
+	}
+	return find(e.Chain)
+}
+
+// Nature has no increased nor decreased stat when it is neutral
+type Nature struct {
+	Name          string        `json:"name"`
+	IncreasedStat NamedResource `json:"increased_stat"`
+	DecreasedStat NamedResource `json:"decreased_stat"`
+}
+
+type GrowthRate struct {
+	Name   string `json:"name"`
+	Levels []struct {
+		Level      int `json:"level"`
+		Experience int `json:"experience"`
+	} `json:"levels"`
+}
+
+// Curve returns the total experience needed to reach each level
+func (g GrowthRate) Curve() pokedex.GrowthCurve {
+	curve := make(pokedex.GrowthCurve, pokedex.MAX_LEVEL+1)
+	for _, level := range g.Levels {
+		if level.Level > 0 && level.Level <= pokedex.MAX_LEVEL {
+			curve[level.Level] = level.Experience
+		}
+	}
+	return curve
+}
+
+type Move struct {
+	Name        string        `json:"name"`
+	Power       int           `json:"power"`
+	Accuracy    int           `json:"accuracy"`
+	Priority    int           `json:"priority"`
+	PP          int           `json:"pp"`
+	Type        NamedResource `json:"type"`
+	DamageClass NamedResource `json:"damage_class"`
+	Meta        struct {
+		Ailment       NamedResource `json:"ailment"`
+		AilmentChance int           `json:"ailment_chance"`
+		CritRate      int           `json:"crit_rate"`
+	} `json:"meta"`
+}
+
+type Pokedex struct {
+	Name           string `json:"name"`
+	PokemonEntries []struct {
+		EntryNumber    int           `json:"entry_number"`
+		PokemonSpecies NamedResource `json:"pokemon_species"`
+	} `json:"pokemon_entries"`
+}
+
+type Generation struct {
+	Name           string          `json:"name"`
+	MainRegion     NamedResource   `json:"main_region"`
+	PokemonSpecies []NamedResource `json:"pokemon_species"`
+}
+
+type EncounterDetail struct {
+	Chance   int           `json:"chance"`
+	MinLevel int           `json:"min_level"`
+	MaxLevel int           `json:"max_level"`
+	Method   NamedResource `json:"method"`
+}
+
+type VersionEncounterDetail struct {
+	Version          NamedResource     `json:"version"`
+	MaxChance        int               `json:"max_chance"`
+	EncounterDetails []EncounterDetail `json:"encounter_details"`
+}
+
+type LocationAreaEncounter struct {
+	LocationArea   NamedResource            `json:"location_area"`
+	VersionDetails []VersionEncounterDetail `json:"version_details"`
+}
+
 type PokemonEncounter struct {
-	Name   string
-	Chance int
+	Name     string
+	Chance   int
+	MinLevel int
+	MaxLevel int
 }
 
 func GetLocationArea(endpoint string) (LocationArea, error) {
@@ -128,6 +317,12 @@ func GetPokemonEncounters(endpoint string) ([]PokemonEncounter, error) {
 			Name:   e.Pokemon.Name,
 			Chance: e.VersionDetails[0].Chance,
 		}
+		for i, detail := range e.VersionDetails[0].EncounterDetails {
+			if i == 0 || detail.MinLevel < encounter.MinLevel {
+				encounter.MinLevel = detail.MinLevel
+			}
+			encounter.MaxLevel = max(encounter.MaxLevel, detail.MaxLevel)
+		}
 		encounters = append(encounters, encounter)
 	}
 	return encounters, nil
@@ -185,3 +380,120 @@ func GetPokemon(endpoint string) (pokedex.Pokemon, error) {
 	}
 	return pokemon, nil
 }
+
+// getJSON fetches an endpoint and decodes its JSON body into v
+func getJSON(endpoint string, v any) error {
+	res, err := http.Get(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to get response %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response body: %w", err)
+	}
+	if res.StatusCode > 299 {
+		return fmt.Errorf("failed response with status code: %d", res.StatusCode)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+func GetRegions(endpoint string) (NamedResources, error) {
+	regions := NamedResources{}
+	if err := getJSON(endpoint, &regions); err != nil {
+		return regions, fmt.Errorf("failed to get regions: %w", err)
+	}
+	return regions, nil
+}
+
+func GetRegion(endpoint string) (Region, error) {
+	region := Region{}
+	if err := getJSON(endpoint, &region); err != nil {
+		return region, fmt.Errorf("failed to get region: %w", err)
+	}
+	return region, nil
+}
+
+func GetLocation(endpoint string) (Location, error) {
+	location := Location{}
+	if err := getJSON(endpoint, &location); err != nil {
+		return location, fmt.Errorf("failed to get location: %w", err)
+	}
+	return location, nil
+}
+
+func GetLocationAreaEncounters(endpoint string) ([]LocationAreaEncounter, error) {
+	encounters := []LocationAreaEncounter{}
+	if err := getJSON(endpoint, &encounters); err != nil {
+		return encounters, fmt.Errorf("failed to get location area encounters: %w", err)
+	}
+	return encounters, nil
+}
+
+func GetPokemonSpecies(endpoint string) (PokemonSpecies, error) {
+	species := PokemonSpecies{}
+	if err := getJSON(endpoint, &species); err != nil {
+		return species, fmt.Errorf("failed to get pokemon species: %w", err)
+	}
+	return species, nil
+}
+
+func GetMove(endpoint string) (Move, error) {
+	move := Move{}
+	if err := getJSON(endpoint, &move); err != nil {
+		return move, fmt.Errorf("failed to get move: %w", err)
+	}
+	return move, nil
+}
+
+func GetPokedex(endpoint string) (Pokedex, error) {
+	pokedex := Pokedex{}
+	if err := getJSON(endpoint, &pokedex); err != nil {
+		return pokedex, fmt.Errorf("failed to get pokedex: %w", err)
+	}
+	return pokedex, nil
+}
+
+func GetGenerations(endpoint string) (NamedResources, error) {
+	generations := NamedResources{}
+	if err := getJSON(endpoint, &generations); err != nil {
+		return generations, fmt.Errorf("failed to get generations: %w", err)
+	}
+	return generations, nil
+}
+
+func GetGeneration(endpoint string) (Generation, error) {
+	generation := Generation{}
+	if err := getJSON(endpoint, &generation); err != nil {
+		return generation, fmt.Errorf("failed to get generation: %w", err)
+	}
+	return generation, nil
+}
+
+func GetGrowthRate(endpoint string) (GrowthRate, error) {
+	growthRate := GrowthRate{}
+	if err := getJSON(endpoint, &growthRate); err != nil {
+		return growthRate, fmt.Errorf("failed to get growth rate: %w", err)
+	}
+	return growthRate, nil
+}
+
+func GetNature(endpoint string) (Nature, error) {
+	nature := Nature{}
+	if err := getJSON(endpoint, &nature); err != nil {
+		return nature, fmt.Errorf("failed to get nature: %w", err)
+	}
+	return nature, nil
+}
+
+func GetEvolutionChain(endpoint string) (EvolutionChain, error) {
+	chain := EvolutionChain{}
+	if err := getJSON(endpoint, &chain); err != nil {
+		return chain, fmt.Errorf("failed to get evolution chain: %w", err)
+	}
+	return chain, nil
+}
@@ -0,0 +1,68 @@
+package battle
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charlesaraya/pokedex-go/internal/pokedex"
+)
+
+const (
+	BALL_POKE   string = "poke-ball"
+	BALL_GREAT  string = "great-ball"
+	BALL_ULTRA  string = "ultra-ball"
+	BALL_MASTER string = "master-ball"
+	BALL_NET    string = "net-ball"
+	BALL_DUSK   string = "dusk-ball"
+	BALL_QUICK  string = "quick-ball"
+	BALL_TIMER  string = "timer-ball"
+)
+
+// BallContext holds the circumstances some balls get a bonus from
+type BallContext struct {
+	Turn  int       // throw turn, starting at 1
+	Time  time.Time // time of the throw
+	Types []string  // types of the targeted Pokémon
+}
+
+// Balls lists the supported balls, in bag order
+var Balls = []string{BALL_POKE, BALL_GREAT, BALL_ULTRA, BALL_MASTER, BALL_NET, BALL_DUSK, BALL_QUICK, BALL_TIMER}
+
+// IsNight reports whether a time falls at night, when Dusk Balls work best
+func IsNight(t time.Time) bool {
+	return pokedex.TimeOfDay(t) == pokedex.TIME_NIGHT
+}
+
+// BallModifier returns the catch rate modifier of a ball in a given context
+func BallModifier(ball string, ctx BallContext) (float64, error) {
+	switch ball {
+	case BALL_POKE:
+		return 1, nil
+	case BALL_GREAT:
+		return 1.5, nil
+	case BALL_ULTRA:
+		return 2, nil
+	case BALL_MASTER:
+		return MASTER_BALL_MODIFIER, nil
+	case BALL_NET:
+		for _, t := range ctx.Types {
+			if t == "water" || t == "bug" {
+				return 3.5, nil
+			}
+		}
+		return 1, nil
+	case BALL_DUSK:
+		if IsNight(ctx.Time) {
+			return 3, nil
+		}
+		return 1, nil
+	case BALL_QUICK:
+		if ctx.Turn <= 1 {
+			return 5, nil
+		}
+		return 1, nil
+	case BALL_TIMER:
+		return min(1+float64(max(ctx.Turn-1, 0))*1229/4096, 4), nil
+	}
+	return 0, fmt.Errorf("unknown ball %q", ball)
+}
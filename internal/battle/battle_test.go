@@ -0,0 +1,338 @@
+package battle
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/charlesaraya/pokedex-go/internal/pokedex"
+)
+
+func TestCapture(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     Capture
+		expectedA float64
+		expectedB int
+	}{
+		{
+			name:      "full hp poke ball",
+			input:     Capture{CaptureRate: 45, Ball: 1, MaxHP: 30, HP: 30, Status: 1},
+			expectedA: 15,
+			expectedB: 32274,
+		},
+		{
+			name:      "one hp",
+			input:     Capture{CaptureRate: 45, Ball: 1, MaxHP: 30, HP: 1, Status: 1},
+			expectedA: 44,
+			expectedB: 42237,
+		},
+		{
+			name:      "guaranteed catch",
+			input:     Capture{CaptureRate: 255, Ball: 2, MaxHP: 30, HP: 1, Status: 2.5},
+			expectedA: 1246.67,
+			expectedB: SHAKE_RANGE,
+		},
+	}
+	for _, c := range cases {
+		a := c.input.ModifiedCatchRate()
+		if math.Abs(a-c.expectedA) > 0.01 {
+			t.Errorf("%s: got a=%v want %v", c.name, a, c.expectedA)
+		}
+		if b := ShakeThreshold(a); b != c.expectedB {
+			t.Errorf("%s: got b=%v want %v", c.name, b, c.expectedB)
+		}
+	}
+
+	t.Run("shake checks", func(t *testing.T) {
+		capture := Capture{CaptureRate: 45, Ball: 1, MaxHP: 30, HP: 30, Status: 1}
+		rolls := []int{0, 0, 65535, 0}
+		i := 0
+		roll := func(n int) int {
+			r := rolls[i]
+			i++
+			return r
+		}
+		if shakes := capture.Shakes(roll); shakes != 2 || Caught(shakes) {
+			t.Errorf("got %d shakes want 2", shakes)
+		}
+		if shakes := capture.Shakes(func(n int) int { return 0 }); !Caught(shakes) {
+			t.Errorf("got %d shakes, expected a catch", shakes)
+		}
+	})
+
+	t.Run("master ball", func(t *testing.T) {
+		worst := func(n int) int { return n - 1 }
+		for maxHP := 1; maxHP <= 300; maxHP++ {
+			for _, status := range []float64{1, 1.5, 2.5} {
+				capture := Capture{CaptureRate: 3, Ball: MASTER_BALL_MODIFIER, MaxHP: maxHP, HP: maxHP, Status: status}
+				if shakes := capture.Shakes(worst); !Caught(shakes) {
+					t.Fatalf("got %d shakes at %d full HP and status %v, a Master Ball never fails", shakes, maxHP, status)
+				}
+			}
+		}
+	})
+}
+
+func TestBallModifier(t *testing.T) {
+	day := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC)
+	cases := []struct {
+		ball     string
+		ctx      BallContext
+		expected float64
+	}{
+		{ball: BALL_POKE, ctx: BallContext{Turn: 1, Time: day}, expected: 1},
+		{ball: BALL_GREAT, ctx: BallContext{Turn: 1, Time: day}, expected: 1.5},
+		{ball: BALL_ULTRA, ctx: BallContext{Turn: 1, Time: day}, expected: 2},
+		{ball: BALL_NET, ctx: BallContext{Turn: 1, Time: day, Types: []string{"fire"}}, expected: 1},
+		{ball: BALL_NET, ctx: BallContext{Turn: 1, Time: day, Types: []string{"bug", "flying"}}, expected: 3.5},
+		{ball: BALL_DUSK, ctx: BallContext{Turn: 1, Time: day}, expected: 1},
+		{ball: BALL_DUSK, ctx: BallContext{Turn: 1, Time: night}, expected: 3},
+		{ball: BALL_QUICK, ctx: BallContext{Turn: 1, Time: day}, expected: 5},
+		{ball: BALL_QUICK, ctx: BallContext{Turn: 2, Time: day}, expected: 1},
+		{ball: BALL_TIMER, ctx: BallContext{Turn: 1, Time: day}, expected: 1},
+		{ball: BALL_TIMER, ctx: BallContext{Turn: 30, Time: day}, expected: 4},
+	}
+	for _, c := range cases {
+		got, err := BallModifier(c.ball, c.ctx)
+		if err != nil {
+			t.Errorf("%s: %v", c.ball, err)
+		}
+		if got != c.expected {
+			t.Errorf("%s: got %v want %v", c.ball, got, c.expected)
+		}
+	}
+	if _, err := BallModifier("cherish-ball", BallContext{}); err == nil {
+		t.Errorf("expected an error for an unknown ball")
+	}
+	master := Capture{CaptureRate: 3, Ball: float64(MAX_CATCH_RATE), MaxHP: 100, HP: 100, Status: 1}
+	if shakes := master.Shakes(func(n int) int { return n - 1 }); !Caught(shakes) {
+		t.Errorf("master ball should never fail")
+	}
+}
+
+func TestEncounter(t *testing.T) {
+	pikachu := pokedex.Pokemon{
+		Name:       "pikachu",
+		Experience: 112,
+		Stats: []pokedex.PokemonStat{
+			{Stat: struct {
+				Name string `json:"name"`
+			}{Name: pokedex.STAT_HP}, Base: 35},
+			{Stat: struct {
+				Name string `json:"name"`
+			}{Name: pokedex.STAT_SPEED}, Base: 90},
+		},
+	}
+	encounter := NewEncounter(pikachu, &pokedex.Individual{Species: "pikachu", Level: 5}, 190)
+	if encounter.MaxHP != 18 || encounter.Wild.HP != encounter.MaxHP {
+		t.Errorf("got %d/%d HP want 18/18", encounter.Wild.HP, encounter.MaxHP)
+	}
+	if encounter.Turn != 1 {
+		t.Errorf("got turn %d want 1", encounter.Turn)
+	}
+	if !encounter.Flees(func(n int) int { return 89 }) {
+		t.Errorf("pikachu should have fled")
+	}
+	if encounter.Flees(func(n int) int { return 90 }) {
+		t.Errorf("pikachu shouldn't have fled")
+	}
+	if got := encounter.Experience(); got != 80 {
+		t.Errorf("got %d XP want 80", got)
+	}
+}
+
+func TestEffectiveness(t *testing.T) {
+	cases := []struct {
+		attacking string
+		defending []string
+		expected  float64
+	}{
+		{attacking: "electric", defending: []string{"water", "flying"}, expected: 4},
+		{attacking: "electric", defending: []string{"ground", "flying"}, expected: 0},
+		{attacking: "fire", defending: []string{"water"}, expected: 0.5},
+		{attacking: "normal", defending: []string{"normal"}, expected: 1},
+	}
+	for _, c := range cases {
+		if got := Effectiveness(c.attacking, c.defending); got != c.expected {
+			t.Errorf("%s against %v: got %v want %v", c.attacking, c.defending, got, c.expected)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	if got := SuperEffective([]string{"electric", "grass", "electric", "normal"}, "water"); len(got) != 2 || got[0] != "electric" || got[1] != "grass" {
+		t.Errorf("got %v hitting water super effectively want [electric grass]", got)
+	}
+	if got := SuperEffective([]string{"normal"}, "ghost"); len(got) != 0 {
+		t.Errorf("got %v hitting ghost super effectively want none", got)
+	}
+	// pikachu, gyarados and charizard
+	matchups := Defense([][]string{{"electric"}, {"water", "flying"}, {"fire", "flying"}})
+	cases := []struct {
+		attacking string
+		weak      int
+		resist    int
+		shared    bool
+	}{
+		{attacking: "rock", weak: 2, resist: 0, shared: true},
+		{attacking: "electric", weak: 2, resist: 1, shared: true},
+		{attacking: "ground", weak: 1, resist: 2, shared: false},
+		{attacking: "fighting", weak: 0, resist: 2, shared: false},
+		{attacking: "normal", weak: 0, resist: 0, shared: false},
+	}
+	for _, c := range cases {
+		i := slices.Index(Types, c.attacking)
+		if m := matchups[i]; m.Type != c.attacking || m.Weak != c.weak || m.Resist != c.resist || m.SharedWeakness() != c.shared {
+			t.Errorf("got %+v shared %v want %d weak, %d resisting, shared %v", m, m.SharedWeakness(), c.weak, c.resist, c.shared)
+		}
+	}
+}
+
+func TestDamage(t *testing.T) {
+	attacker := Combatant{Name: "pikachu", Level: 50, Types: []string{"electric"}, Attack: 100, SpAttack: 100, Speed: 110}
+	defender := Combatant{Name: "gyarados", Level: 50, Types: []string{"water"}, Defense: 100, SpDefense: 100, Speed: 80}
+	thunderbolt := Move{Name: "thunderbolt", Type: "electric", Class: CLASS_SPECIAL, Power: 80, Accuracy: 100}
+	// rolls: accuracy, critical hit, random factor
+	rolls := func(values ...int) func(n int) int {
+		return func(n int) int {
+			r := values[0]
+			values = values[1:]
+			return r
+		}
+	}
+
+	if hit := Damage(attacker, defender, thunderbolt, rolls(0, 1, 15)); hit.Damage != 111 || hit.Critical || hit.Effectiveness != 2 {
+		t.Errorf("got %+v want 111 damage", hit)
+	}
+	if hit := Damage(attacker, defender, thunderbolt, rolls(0, 0, 15)); hit.Damage != 166 || !hit.Critical {
+		t.Errorf("got %+v want a 166 damage critical hit", hit)
+	}
+	if hit := Damage(attacker, defender, thunderbolt, rolls(0, 1, 0)); hit.Damage != 94 {
+		t.Errorf("got %+v want 94 damage with the lowest random factor", hit)
+	}
+	if hit := Damage(attacker, defender, Move{Type: "electric", Class: CLASS_SPECIAL, Power: 80, Accuracy: 70}, rolls(70)); !hit.Missed {
+		t.Errorf("got %+v want a miss", hit)
+	}
+
+	t.Run("turn order", func(t *testing.T) {
+		quickAttack := Move{Name: "quick-attack", Type: "normal", Power: 40, Priority: 1}
+		if !MovesFirst(attacker, thunderbolt, defender, thunderbolt, rolls(1)) {
+			t.Errorf("the fastest Pokémon should move first")
+		}
+		if !MovesFirst(defender, quickAttack, attacker, thunderbolt, rolls(1)) {
+			t.Errorf("the highest priority move should go first")
+		}
+	})
+}
+
+func TestStatus(t *testing.T) {
+	always := func(n int) int { return 0 }
+	never := func(n int) int { return n - 1 }
+
+	t.Run("apply ailments", func(t *testing.T) {
+		status := pokedex.Status{}
+		if ApplyAilment(&status, []string{"fire"}, STATUS_BURN, 0, always) {
+			t.Errorf("fire types can't be burned")
+		}
+		if ApplyAilment(&status, []string{"normal"}, STATUS_BURN, 10, never) {
+			t.Errorf("burn shouldn't apply on a failed roll")
+		}
+		if !ApplyAilment(&status, []string{"normal"}, STATUS_BURN, 10, always) || status.Condition != STATUS_BURN {
+			t.Errorf("got %+v want a burn", status)
+		}
+		if ApplyAilment(&status, []string{"normal"}, STATUS_POISON, 0, always) {
+			t.Errorf("a Pokémon can only suffer one status at a time")
+		}
+		if ApplyAilment(&pokedex.Status{}, []string{"normal"}, "confusion", 0, always) {
+			t.Errorf("confusion isn't a status condition")
+		}
+	})
+
+	t.Run("sleep wears off", func(t *testing.T) {
+		status := pokedex.Status{}
+		ApplyAilment(&status, nil, STATUS_SLEEP, 0, always)
+		if status.Turns != SLEEP_MIN_TURNS {
+			t.Errorf("got %d turns asleep want %d", status.Turns, SLEEP_MIN_TURNS)
+		}
+		if ok, _ := CanMove("snorlax", &status, always); ok {
+			t.Errorf("a sleeping Pokémon can't move")
+		}
+		if ok, _ := CanMove("snorlax", &status, always); !ok || status.Condition != "" {
+			t.Errorf("got %+v, snorlax should have woken up", status)
+		}
+	})
+
+	t.Run("paralysis", func(t *testing.T) {
+		status := pokedex.Status{Condition: STATUS_PARALYSIS}
+		if ok, _ := CanMove("pikachu", &status, always); ok {
+			t.Errorf("got a move, want full paralysis")
+		}
+		if ok, _ := CanMove("pikachu", &status, never); !ok {
+			t.Errorf("paralysis shouldn't always stop a Pokémon")
+		}
+		if speed := (Combatant{Speed: 100, Status: STATUS_PARALYSIS}).EffectiveSpeed(); speed != 50 {
+			t.Errorf("got speed %d want 50", speed)
+		}
+	})
+
+	t.Run("modifiers and damage", func(t *testing.T) {
+		cases := []struct {
+			condition string
+			capture   float64
+			damage    int
+		}{
+			{condition: "", capture: 1, damage: 0},
+			{condition: STATUS_SLEEP, capture: 2.5, damage: 0},
+			{condition: STATUS_FREEZE, capture: 2.5, damage: 0},
+			{condition: STATUS_PARALYSIS, capture: 1.5, damage: 0},
+			{condition: STATUS_BURN, capture: 1.5, damage: 6},
+			{condition: STATUS_POISON, capture: 1.5, damage: 12},
+		}
+		for _, c := range cases {
+			status := pokedex.Status{Condition: c.condition}
+			if got := CaptureModifier(status); got != c.capture {
+				t.Errorf("%q: got capture modifier %v want %v", c.condition, got, c.capture)
+			}
+			if got := EndOfTurnDamage(status, 100); got != c.damage {
+				t.Errorf("%q: got %d damage want %d", c.condition, got, c.damage)
+			}
+		}
+	})
+}
+
+func TestStats(t *testing.T) {
+	stat := func(name string, base int) pokedex.PokemonStat {
+		s := pokedex.PokemonStat{Base: base}
+		s.Stat.Name = name
+		return s
+	}
+	garchomp := pokedex.Pokemon{
+		Name: "garchomp",
+		Stats: []pokedex.PokemonStat{
+			stat(pokedex.STAT_HP, 108),
+			stat(pokedex.STAT_ATTACK, 130),
+			stat(pokedex.STAT_DEFENSE, 95),
+			stat(pokedex.STAT_SP_ATTACK, 80),
+			stat(pokedex.STAT_SP_DEFENSE, 85),
+			stat(pokedex.STAT_SPEED, 102),
+		},
+	}
+	individual := &pokedex.Individual{
+		Level: 78,
+		IVs:   pokedex.Stats{HP: 24, Attack: 12, Defense: 30, SpecialAttack: 16, SpecialDefense: 23, Speed: 5},
+		EVs:   pokedex.Stats{HP: 74, Attack: 190, Defense: 91, SpecialAttack: 48, SpecialDefense: 84, Speed: 23},
+	}
+	adamant := pokedex.Nature{Name: "adamant", Increased: pokedex.STAT_ATTACK, Decreased: pokedex.STAT_SP_ATTACK}
+	got := Stats(garchomp, individual, adamant)
+	want := pokedex.Stats{HP: 289, Attack: 278, Defense: 193, SpecialAttack: 135, SpecialDefense: 171, Speed: 171}
+	if got != want {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+	neutral := Stats(garchomp, individual, pokedex.Nature{Name: "hardy"})
+	if neutral.Attack != 253 || neutral.SpecialAttack != 151 {
+		t.Errorf("a neutral nature should change nothing, got %+v", neutral)
+	}
+}
@@ -0,0 +1,66 @@
+package battle
+
+import "math"
+
+const (
+	MAX_CATCH_RATE       int     = 255
+	SHAKE_CHECKS         int     = 4
+	SHAKE_RANGE          int     = 65536
+	MASTER_BALL_MODIFIER float64 = 255 // the Master Ball's, which passes every shake check
+)
+
+// Capture holds the inputs of the Generation III+ capture formula
+type Capture struct {
+	CaptureRate int     // species capture_rate, from 3 (legendaries) to 255
+	Ball        float64 // ball modifier, 1 for a Poké Ball
+	MaxHP       int
+	HP          int
+	Status      float64 // status modifier, 1 when healthy
+}
+
+// ModifiedCatchRate returns the modified catch rate "a":
+// ((3*MaxHP - 2*HP) * CaptureRate * Ball) / (3*MaxHP) * Status
+func (c Capture) ModifiedCatchRate() float64 {
+	maxHP := max(c.MaxHP, 1)
+	hp := min(max(c.HP, 1), maxHP)
+	ball := c.Ball
+	if ball == 0 {
+		ball = 1
+	}
+	status := c.Status
+	if status == 0 {
+		status = 1
+	}
+	a := float64(3*maxHP-2*hp) * float64(c.CaptureRate) * ball / float64(3*maxHP) * status
+	return max(a, 1)
+}
+
+// ShakeThreshold returns the shake probability "b" out of SHAKE_RANGE for a modified
+// catch rate: 1048560 / sqrt(sqrt(16711680 / a))
+func ShakeThreshold(a float64) int {
+	if a >= float64(MAX_CATCH_RATE) {
+		return SHAKE_RANGE
+	}
+	return int(1048560 / math.Sqrt(math.Sqrt(16711680/a)))
+}
+
+// Shakes rolls the four shake checks and returns how many of them passed. The
+// Pokémon is caught when all SHAKE_CHECKS pass. roll returns a random number in
+// [0, n), e.g. rand.Intn.
+func (c Capture) Shakes(roll func(n int) int) int {
+	// the Master Ball never fails, whatever the formula rounds to
+	if c.Ball >= MASTER_BALL_MODIFIER {
+		return SHAKE_CHECKS
+	}
+	b := ShakeThreshold(c.ModifiedCatchRate())
+	shakes := 0
+	for shakes < SHAKE_CHECKS && roll(SHAKE_RANGE) < b {
+		shakes++
+	}
+	return shakes
+}
+
+// Caught reports whether a shake count means the Pokémon was caught
+func Caught(shakes int) bool {
+	return shakes >= SHAKE_CHECKS
+}
@@ -0,0 +1,45 @@
+package battle
+
+import "slices"
+
+// SuperEffective returns the attacking types that hit a defending type super effectively
+func SuperEffective(attacking []string, defending string) []string {
+	hits := []string{}
+	for _, t := range attacking {
+		if Effectiveness(t, []string{defending}) > 1 && !slices.Contains(hits, t) {
+			hits = append(hits, t)
+		}
+	}
+	return hits
+}
+
+// Matchup counts the members of a team weak to an attacking type and those resisting
+// it, immune ones included
+type Matchup struct {
+	Type   string
+	Weak   int
+	Resist int
+}
+
+// SharedWeakness reports whether several members of the team are weak to the type
+func (m Matchup) SharedWeakness() bool {
+	return m.Weak > 1
+}
+
+// Defense returns how a team, given by the types of each member, fares against each
+// of the 18 types
+func Defense(team [][]string) []Matchup {
+	matchups := make([]Matchup, len(Types))
+	for i, attacking := range Types {
+		matchups[i].Type = attacking
+		for _, defending := range team {
+			switch multiplier := Effectiveness(attacking, defending); {
+			case multiplier > 1:
+				matchups[i].Weak++
+			case multiplier < 1:
+				matchups[i].Resist++
+			}
+		}
+	}
+	return matchups
+}
@@ -0,0 +1,136 @@
+package battle
+
+import (
+	"slices"
+
+	"github.com/charlesaraya/pokedex-go/internal/pokedex"
+)
+
+const (
+	CLASS_PHYSICAL string = "physical"
+	CLASS_SPECIAL  string = "special"
+	CLASS_STATUS   string = "status"
+)
+
+// Move holds the battle data of a move
+type Move struct {
+	Name      string
+	Type      string
+	Class     string // physical, special or status
+	Power     int
+	Accuracy  int // 0 never misses
+	Priority  int
+	CritStage int
+	Ailment   string
+	Chance    int // ailment chance out of 100, 0 always
+}
+
+// Combatant is a Pokémon taking part in a battle
+type Combatant struct {
+	Name      string
+	Level     int
+	Types     []string
+	Attack    int
+	Defense   int
+	SpAttack  int
+	SpDefense int
+	Speed     int
+	Status    string
+}
+
+// EffectiveSpeed returns the speed of a combatant, halved when paralyzed
+func (c Combatant) EffectiveSpeed() int {
+	if c.Status == STATUS_PARALYSIS {
+		return c.Speed / 2
+	}
+	return c.Speed
+}
+
+// NewCombatant computes the battle stats of a Pokémon
+func NewCombatant(pokemon pokedex.Pokemon, individual *pokedex.Individual, nature pokedex.Nature) Combatant {
+	types := make([]string, len(pokemon.Types))
+	for i, pokemonType := range pokemon.Types {
+		types[i] = pokemonType.Type.Name
+	}
+	stats := Stats(pokemon, individual, nature)
+	return Combatant{
+		Name:      individual.Name(),
+		Level:     individual.Level,
+		Types:     types,
+		Attack:    stats.Attack,
+		Defense:   stats.Defense,
+		SpAttack:  stats.SpecialAttack,
+		SpDefense: stats.SpecialDefense,
+		Speed:     stats.Speed,
+		Status:    individual.Status.Condition,
+	}
+}
+
+type Hit struct {
+	Damage        int
+	Missed        bool
+	Critical      bool
+	Effectiveness float64
+}
+
+// critChance returns the 1-in-n odds of a critical hit by stage (Gen VII+)
+func critChance(stage int) int {
+	switch {
+	case stage <= 0:
+		return 24
+	case stage == 1:
+		return 8
+	case stage == 2:
+		return 2
+	}
+	return 1
+}
+
+// Damage rolls a move used by an attacker against a defender with the standard damage
+// formula: ((2*Level/5+2) * Power * A/D / 50 + 2) * crit * random * STAB * type * burn.
+// roll returns a random number in [0, n), e.g. rand.Intn.
+func Damage(attacker Combatant, defender Combatant, move Move, roll func(n int) int) Hit {
+	hit := Hit{Effectiveness: Effectiveness(move.Type, defender.Types)}
+	if move.Accuracy > 0 && roll(100) >= move.Accuracy {
+		hit.Missed = true
+		return hit
+	}
+	if move.Class == CLASS_STATUS || move.Power == 0 {
+		return hit
+	}
+	a, d := attacker.Attack, defender.Defense
+	if move.Class == CLASS_SPECIAL {
+		a, d = attacker.SpAttack, defender.SpDefense
+	}
+	damage := float64((2*attacker.Level/5+2)*move.Power*a/max(d, 1)/50 + 2)
+	if roll(critChance(move.CritStage)) == 0 {
+		hit.Critical = true
+		damage *= 1.5
+	}
+	damage = damage * float64(85+roll(16)) / 100
+	if slices.Contains(attacker.Types, move.Type) {
+		damage *= 1.5
+	}
+	damage *= hit.Effectiveness
+	if attacker.Status == STATUS_BURN && move.Class == CLASS_PHYSICAL {
+		damage /= 2
+	}
+	hit.Damage = int(damage)
+	if hit.Damage == 0 && hit.Effectiveness > 0 {
+		hit.Damage = 1
+	}
+	return hit
+}
+
+// MovesFirst reports whether the first combatant acts before the second: the move
+// with the highest priority goes first, then the fastest Pokémon (paralysis halves
+// speed), with speed ties broken at random
+func MovesFirst(first Combatant, firstMove Move, second Combatant, secondMove Move, roll func(n int) int) bool {
+	if firstMove.Priority != secondMove.Priority {
+		return firstMove.Priority > secondMove.Priority
+	}
+	if first.EffectiveSpeed() != second.EffectiveSpeed() {
+		return first.EffectiveSpeed() > second.EffectiveSpeed()
+	}
+	return roll(2) == 0
+}
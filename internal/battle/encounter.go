@@ -0,0 +1,63 @@
+package battle
+
+import "github.com/charlesaraya/pokedex-go/internal/pokedex"
+
+const (
+	FLEE_RANGE int = 512
+)
+
+// Encounter is the state of a wild Pokémon the player is facing
+type Encounter struct {
+	Pokemon     pokedex.Pokemon
+	Wild        *pokedex.Individual
+	CaptureRate int
+	MaxHP       int
+	Turn        int
+}
+
+// NewEncounter starts an encounter on its first turn, with the wild Pokémon at full HP
+func NewEncounter(pokemon pokedex.Pokemon, wild *pokedex.Individual, captureRate int) *Encounter {
+	maxHP := MaxHP(pokemon, wild)
+	wild.HP = maxHP
+	return &Encounter{
+		Pokemon:     pokemon,
+		Wild:        wild,
+		CaptureRate: captureRate,
+		MaxHP:       maxHP,
+		Turn:        1,
+	}
+}
+
+// Capture returns the capture formula inputs for throwing a ball at the wild Pokémon
+func (e *Encounter) Capture(ball float64) Capture {
+	return Capture{
+		CaptureRate: e.CaptureRate,
+		Ball:        ball,
+		MaxHP:       e.MaxHP,
+		HP:          e.Wild.HP,
+		Status:      CaptureModifier(e.Wild.Status),
+	}
+}
+
+// FleeChance returns the chance, out of FLEE_RANGE, the wild Pokémon flees after a
+// failed throw. Like in the Safari Zone, faster species are more likely to run away.
+func (e *Encounter) FleeChance() int {
+	return min(e.Pokemon.BaseStat(pokedex.STAT_SPEED), FLEE_RANGE)
+}
+
+// Flees rolls whether the wild Pokémon runs away. roll returns a random number in
+// [0, n), e.g. rand.Intn.
+func (e *Encounter) Flees(roll func(n int) int) bool {
+	return roll(FLEE_RANGE) < e.FleeChance()
+}
+
+// ExperienceYield returns the experience earned by defeating or catching a wild
+// Pokémon, from its species' base experience and its level
+func ExperienceYield(baseExperience int, level int) int {
+	return max(baseExperience*level/7, 1)
+}
+
+// Experience returns the experience the wild Pokémon yields
+func (e *Encounter) Experience() int {
+	return ExperienceYield(e.Pokemon.Experience, e.Wild.Level)
+}
@@ -0,0 +1,34 @@
+package battle
+
+import "github.com/charlesaraya/pokedex-go/internal/pokedex"
+
+// HPStat returns the max HP of a Pokémon from its base HP, IV, EV and level
+func HPStat(base int, iv int, ev int, level int) int {
+	return (2*base+iv+ev/4)*level/100 + level + 10
+}
+
+// Stat returns a non-HP stat of a Pokémon from its base stat, IV, EV and level,
+// adjusted by its nature's modifier in percent
+func Stat(base int, iv int, ev int, level int, nature int) int {
+	return ((2*base+iv+ev/4)*level/100 + 5) * nature / 100
+}
+
+// MaxHP returns the max HP of a Pokémon
+func MaxHP(pokemon pokedex.Pokemon, individual *pokedex.Individual) int {
+	return HPStat(pokemon.BaseStat(pokedex.STAT_HP), individual.IVs.HP, individual.EVs.HP, individual.Level)
+}
+
+// Stats returns the final value of every stat of a Pokémon
+func Stats(pokemon pokedex.Pokemon, individual *pokedex.Individual, nature pokedex.Nature) pokedex.Stats {
+	stat := func(name string) int {
+		return Stat(pokemon.BaseStat(name), individual.IVs.Get(name), individual.EVs.Get(name), individual.Level, nature.Modifier(name))
+	}
+	return pokedex.Stats{
+		HP:             MaxHP(pokemon, individual),
+		Attack:         stat(pokedex.STAT_ATTACK),
+		Defense:        stat(pokedex.STAT_DEFENSE),
+		SpecialAttack:  stat(pokedex.STAT_SP_ATTACK),
+		SpecialDefense: stat(pokedex.STAT_SP_DEFENSE),
+		Speed:          stat(pokedex.STAT_SPEED),
+	}
+}
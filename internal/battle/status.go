@@ -0,0 +1,129 @@
+package battle
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charlesaraya/pokedex-go/internal/pokedex"
+)
+
+const (
+	STATUS_SLEEP     string = "sleep"
+	STATUS_PARALYSIS string = "paralysis"
+	STATUS_BURN      string = "burn"
+	STATUS_POISON    string = "poison"
+	STATUS_FREEZE    string = "freeze"
+)
+
+const (
+	SLEEP_MIN_TURNS    int = 1
+	SLEEP_MAX_TURNS    int = 3
+	THAW_CHANCE        int = 20 // out of 100, each turn
+	FULL_PARALYSIS     int = 25 // out of 100, each turn
+	BURN_DAMAGE_RATIO  int = 16 // 1/16 of max HP per turn
+	POISON_DAMAGE_RATE int = 8  // 1/8 of max HP per turn
+)
+
+// immunities lists the types that can't suffer a status condition
+var immunities = map[string][]string{
+	STATUS_BURN:      {"fire"},
+	STATUS_FREEZE:    {"ice"},
+	STATUS_PARALYSIS: {"electric"},
+	STATUS_POISON:    {"poison", "steel"},
+}
+
+// IsStatus reports whether a move ailment is one of the supported status conditions
+func IsStatus(ailment string) bool {
+	switch ailment {
+	case STATUS_SLEEP, STATUS_PARALYSIS, STATUS_BURN, STATUS_POISON, STATUS_FREEZE:
+		return true
+	}
+	return false
+}
+
+// ApplyAilment tries to inflict a move's ailment on a target. chance is out of 100, and 0
+// means the ailment always applies (status moves). A Pokémon can only suffer one status
+// condition at a time, and some types are immune to some of them.
+func ApplyAilment(target *pokedex.Status, types []string, ailment string, chance int, roll func(n int) int) bool {
+	if !IsStatus(ailment) || target.Condition != "" {
+		return false
+	}
+	for _, t := range types {
+		if slices.Contains(immunities[ailment], t) {
+			return false
+		}
+	}
+	if chance > 0 && roll(100) >= chance {
+		return false
+	}
+	target.Condition = ailment
+	target.Turns = 0
+	if ailment == STATUS_SLEEP {
+		target.Turns = SLEEP_MIN_TURNS + roll(SLEEP_MAX_TURNS-SLEEP_MIN_TURNS+1)
+	}
+	return true
+}
+
+// CanMove checks whether a Pokémon's status lets it act this turn, updating the status
+// as it wears off. It returns a message describing what happened, if anything.
+func CanMove(name string, status *pokedex.Status, roll func(n int) int) (bool, string) {
+	switch status.Condition {
+	case STATUS_SLEEP:
+		if status.Turns > 0 {
+			status.Turns--
+			return false, fmt.Sprintf("%s is fast asleep.", name)
+		}
+		*status = pokedex.Status{}
+		return true, fmt.Sprintf("%s woke up!", name)
+	case STATUS_FREEZE:
+		if roll(100) < THAW_CHANCE {
+			*status = pokedex.Status{}
+			return true, fmt.Sprintf("%s thawed out!", name)
+		}
+		return false, fmt.Sprintf("%s is frozen solid!", name)
+	case STATUS_PARALYSIS:
+		if roll(100) < FULL_PARALYSIS {
+			return false, fmt.Sprintf("%s is paralyzed! It can't move!", name)
+		}
+	}
+	return true, ""
+}
+
+// EndOfTurnDamage returns the HP a Pokémon loses at the end of a turn to its status
+func EndOfTurnDamage(status pokedex.Status, maxHP int) int {
+	switch status.Condition {
+	case STATUS_BURN:
+		return max(maxHP/BURN_DAMAGE_RATIO, 1)
+	case STATUS_POISON:
+		return max(maxHP/POISON_DAMAGE_RATE, 1)
+	}
+	return 0
+}
+
+// CaptureModifier returns the capture formula status modifier
+func CaptureModifier(status pokedex.Status) float64 {
+	switch status.Condition {
+	case STATUS_SLEEP, STATUS_FREEZE:
+		return 2.5
+	case STATUS_PARALYSIS, STATUS_BURN, STATUS_POISON:
+		return 1.5
+	}
+	return 1
+}
+
+// Inflicted returns the message shown when a Pokémon gets a status condition
+func Inflicted(name string, condition string) string {
+	switch condition {
+	case STATUS_SLEEP:
+		return fmt.Sprintf("%s fell asleep!", name)
+	case STATUS_PARALYSIS:
+		return fmt.Sprintf("%s is paralyzed! It may be unable to move!", name)
+	case STATUS_BURN:
+		return fmt.Sprintf("%s was burned!", name)
+	case STATUS_POISON:
+		return fmt.Sprintf("%s was poisoned!", name)
+	case STATUS_FREEZE:
+		return fmt.Sprintf("%s was frozen solid!", name)
+	}
+	return ""
+}
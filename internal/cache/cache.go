@@ -4,12 +4,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/charlesaraya/pokedex-go/internal/battle"
 	"github.com/charlesaraya/pokedex-go/internal/pokedex"
 )
 
 type Cache struct {
 	CachedEntries map[string]*CacheEntry
 	Pokedex       *pokedex.Pokedex
+	Encounter     *battle.Encounter
 	Mu            sync.RWMutex
 }
 
@@ -0,0 +1,254 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charlesaraya/pokedex-go/internal/cache"
+)
+
+// Types of the values of arguments and flags
+const (
+	TYPE_STRING string = "string" // lowercased, like the names of Pokémon and areas
+	TYPE_TEXT   string = "text"   // as typed, like nicknames
+	TYPE_INT    string = "int"
+	TYPE_BOOL   string = "bool" // a flag given without a value
+)
+
+// Positional arguments of commands
+const (
+	ARG_POKEMON   string = "pokemon"
+	ARG_OTHER     string = "other"
+	ARG_AREA      string = "area"
+	ARG_LOCATION  string = "location"
+	ARG_REGION    string = "region"
+	ARG_DIRECTION string = "direction"
+	ARG_VERSION   string = "version"
+	ARG_QUERY     string = "query"
+	ARG_MOVE      string = "move"
+	ARG_ITEM      string = "item"
+	ARG_BOX       string = "box"
+	ARG_NICKNAME  string = "nickname"
+	ARG_ACTION    string = "action"
+	ARG_COMMAND   string = "command"
+)
+
+// ErrUsage is returned when a command is given arguments or flags it doesn't take
+var ErrUsage = errors.New("usage")
+
+// Flag declares a positional argument or a flag of a command. Its type defaults to
+// TYPE_STRING.
+type Flag struct {
+	Name        string   `json:"name"`
+	Value       string   `json:"value,omitempty"` // what the value stands for in the usage, the name by default
+	Type        string   `json:"type,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Variadic    bool     `json:"variadic,omitempty"` // takes every word left
+	Choices     []string `json:"choices,omitempty"`
+	Description string   `json:"description"`
+}
+
+func (f Flag) isFlag() bool {
+	return strings.HasPrefix(f.Name, "-")
+}
+
+// usage returns how the argument or flag is written in the usage of its command
+func (f Flag) usage() string {
+	value := f.Value
+	if value == "" {
+		value = strings.TrimLeft(f.Name, "-")
+	}
+	value = "<" + value + ">"
+	if f.Variadic {
+		value += "..."
+	}
+	if f.isFlag() {
+		value = f.Name + " " + value
+		if f.Type == TYPE_BOOL {
+			value = f.Name
+		}
+	}
+	if !f.Required {
+		value = "[" + value + "]"
+	}
+	return value
+}
+
+// validate checks that a value is of the type and one of the choices of the argument
+// or flag
+func (f Flag) validate(value string) error {
+	what := f.Name
+	if f.isFlag() {
+		what += " value"
+	}
+	if f.Type == TYPE_INT {
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("invalid %s %q, expected a number", what, value)
+		}
+	}
+	if len(f.Choices) > 0 && !slices.Contains(f.Choices, value) {
+		return fmt.Errorf("invalid %s %q, expected %s", what, value, strings.Join(f.Choices, " or "))
+	}
+	return nil
+}
+
+// Values holds the arguments and flags given to a command, by name
+type Values map[string][]string
+
+// Has reports whether an argument or flag was given, or has a default
+func (v Values) Has(name string) bool {
+	return len(v[name]) > 0
+}
+
+// String returns the value of an argument or flag, the last one when given several times
+func (v Values) String(name string) string {
+	if !v.Has(name) {
+		return ""
+	}
+	return v[name][len(v[name])-1]
+}
+
+// Strings returns every value of a variadic argument, or of a flag given several times
+func (v Values) Strings(name string) []string {
+	return v[name]
+}
+
+// Int returns the value of an TYPE_INT argument or flag, which was checked when parsed
+func (v Values) Int(name string) int {
+	n, _ := strconv.Atoi(v.String(name))
+	return n
+}
+
+// Bool reports whether an TYPE_BOOL flag was given
+func (v Values) Bool(name string) bool {
+	return v.String(name) == "true"
+}
+
+// Usage returns how to call a command, from its arguments and flags
+func (command Command) Usage() string {
+	usage := []string{command.Name}
+	for _, arg := range command.Args {
+		usage = append(usage, arg.usage())
+	}
+	for _, flag := range command.Flags {
+		usage = append(usage, flag.usage())
+	}
+	return strings.Join(usage, " ")
+}
+
+// usageError tells what was wrong with what a command was given, and how to use it
+func (command Command) usageError(format string, a ...any) error {
+	return fmt.Errorf("%s, %w: %s", fmt.Sprintf(format, a...), ErrUsage, command.Usage())
+}
+
+// Call parses the words given to a command, as typed, against the arguments and flags
+// it declares, then runs it
+func (command Command) Call(words []string, c *cache.Cache) (Result, error) {
+	values, err := command.parse(words)
+	if err != nil {
+		return nil, err
+	}
+	return command.run(&Invocation{Registry: command.registry, command: command, prompt: command.registry.prompt}, values, c)
+}
+
+// parse parses the words given to a command as typed, lowercasing them but for
+// TYPE_TEXT arguments
+func (command Command) parse(words []string) (Values, error) {
+	params := make([]string, len(words))
+	for i, word := range words {
+		params[i] = strings.ToLower(word)
+	}
+	return command.parseArgs(params, words)
+}
+
+// run runs a command with the values of its arguments and flags
+func (command Command) run(inv *Invocation, values Values, c *cache.Cache) (Result, error) {
+	config := command.Config
+	if config == nil {
+		config = &Config{}
+	}
+	return command.Command(inv, config, values, c)
+}
+
+// parseArgs parses the words given to a command, lowercased as params and as typed as
+// args, into the values of its arguments and flags. Flags may come anywhere.
+func (command Command) parseArgs(params []string, args []string) (Values, error) {
+	if len(args) != len(params) {
+		args = params
+	}
+	values := Values{}
+	// pick takes the value as typed for TYPE_TEXT, lowercased otherwise
+	pick := func(f Flag, from, to int) (string, error) {
+		words := params[from:to]
+		if f.Type == TYPE_TEXT {
+			words = args[from:to]
+		}
+		value := strings.Join(words, " ")
+		if err := f.validate(value); err != nil {
+			return "", command.usageError("%v", err)
+		}
+		return value, nil
+	}
+	positional := 0
+	for i := 0; i < len(params); i++ {
+		if !strings.HasPrefix(params[i], "-") {
+			if positional >= len(command.Args) {
+				return nil, command.usageError("unexpected argument %q", args[i])
+			}
+			arg := command.Args[positional]
+			value, err := pick(arg, i, i+1)
+			if err != nil {
+				return nil, err
+			}
+			values[arg.Name] = append(values[arg.Name], value)
+			if !arg.Variadic {
+				positional++
+			}
+			continue
+		}
+		flag, ok := command.flag(params[i])
+		switch {
+		case !ok:
+			return nil, command.usageError("unknown flag %s", params[i])
+		case flag.Type == TYPE_BOOL:
+			values[flag.Name] = append(values[flag.Name], "true")
+			continue
+		case i+1 >= len(params):
+			return nil, command.usageError("flag %s needs a value", flag.Name)
+		}
+		to := i + 2
+		if flag.Variadic {
+			to = len(params)
+		}
+		value, err := pick(flag, i+1, to)
+		if err != nil {
+			return nil, err
+		}
+		values[flag.Name] = append(values[flag.Name], value)
+		i = to - 1
+	}
+	for _, f := range append(append([]Flag{}, command.Args...), command.Flags...) {
+		switch {
+		case values.Has(f.Name):
+		case f.Required:
+			return nil, command.usageError("missing %s", f.usage())
+		case f.Default != "":
+			values[f.Name] = []string{f.Default}
+		}
+	}
+	return values, nil
+}
+
+// flag returns a flag of a command by name, its own or one of the commonFlags
+func (command Command) flag(name string) (Flag, bool) {
+	for _, flag := range append(append([]Flag{}, command.Flags...), commonFlags...) {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+	return Flag{}, false
+}
@@ -2,16 +2,26 @@ package commands
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charlesaraya/pokedex-go/internal/api"
+	"github.com/charlesaraya/pokedex-go/internal/battle"
 	"github.com/charlesaraya/pokedex-go/internal/cache"
 	"github.com/charlesaraya/pokedex-go/internal/pokedex"
+	"github.com/charlesaraya/pokedex-go/internal/query"
+	"github.com/charlesaraya/pokedex-go/internal/render"
 	"github.com/charlesaraya/pokedex-go/internal/session"
 	"github.com/charlesaraya/pokedex-go/internal/terminal"
+	"github.com/charlesaraya/pokedex-go/internal/world"
 )
 
 const (
@@ -30,32 +40,264 @@ const (
 	FLAG_WHEREAMI_L string = "-l"
 	CMD_VISIT       string = "visit"
 	CMD_ENCOUNTER   string = "encounter"
+	CMD_REGIONS     string = "regions"
+	CMD_LOCATIONS   string = "locations"
+	CMD_AREAS       string = "areas"
+	CMD_WHERE       string = "where"
+	CMD_LOOK        string = "look"
+	CMD_GO          string = "go"
+	CMD_ROUTE       string = "route"
+	CMD_HUNT        string = "hunt"
+	CMD_VERSION     string = "version"
+	FLAG_AVOID      string = "--avoid"
+	FLAG_COST       string = "--cost"
+	CMD_BAG         string = "bag"
+	FLAG_BALL       string = "--ball"
+	CMD_RUN         string = "run"
+	CMD_FIGHT       string = "fight"
+	CMD_LEAD        string = "lead"
+	CMD_HEAL        string = "heal"
+	FLAG_PROGRESS   string = "--progress"
+	CMD_PARTY       string = "party"
+	CMD_BOX         string = "box"
+	FLAG_NAME       string = "--name"
+	CMD_DEPOSIT     string = "deposit"
+	CMD_WITHDRAW    string = "withdraw"
+	CMD_SWAP        string = "swap"
+	CMD_RELEASE     string = "release"
+	CMD_USE         string = "use"
+	CMD_TRADE       string = "trade"
+	CMD_RENAME      string = "rename"
+	CMD_SHINY       string = "shiny"
+	FLAG_SHINY      string = "--shiny"
+	FLAG_ODDS       string = "--odds"
+	CMD_COMPARE     string = "compare"
+	FLAG_JSON       string = "--json"
+	CMD_TEAM        string = "team"
+	CMD_ANALYZE     string = "analyze"
+	FALLBACK_MOVE   string = "tackle"
 )
 
+const (
+	MAX_BASE_STAT   int = 255 // highest base stat of any species, the full length of a stat bar
+	STAT_BAR_WIDTH  int = 15
+	MAX_SUGGESTIONS int = 3 // boxed Pokémon suggested to close the gaps of the party
+	MAX_FOUND_IN    int = 3 // areas shown for a missing species, the rest are counted
+)
+
+// Fields of your Pokémon that list commands can filter and sort by
+const (
+	FIELD_SPECIES  string = "species"
+	FIELD_NICKNAME string = "nickname"
+	FIELD_ID       string = "id"
+	FIELD_TYPE     string = "type"
+	FIELD_STAT     string = "stat."
+	FIELD_LEVEL    string = "level"
+	FIELD_XP       string = "xp"
+	FIELD_HEIGHT   string = "height"
+	FIELD_WEIGHT   string = "weight"
+	FIELD_CAUGHT   string = "caught"
+	FIELD_LOCATION string = "location"
+	FIELD_BALL     string = "ball"
+	FIELD_SHINY    string = "shiny"
+	FIELD_GENDER   string = "gender"
+	FIELD_NATURE   string = "nature"
+	FIELD_ABILITY  string = "ability"
+)
+
+const (
+	FLAG_OUTPUT      string = "-o"
+	FLAG_OUTPUT_LONG string = "--output"
+)
+
+// commonFlags are the flags every command takes, on top of the ones it declares
+var commonFlags = []Flag{
+	{
+		Name:        FLAG_OUTPUT,
+		Value:       "format",
+		Choices:     render.Formats,
+		Description: "Renders the result in a format, overriding --output of the Pokedex.",
+	},
+	{
+		Name:        FLAG_OUTPUT_LONG,
+		Value:       "format",
+		Choices:     render.Formats,
+		Description: "Same as -o.",
+	},
+	{
+		Name:        FLAG_JSON,
+		Type:        TYPE_BOOL,
+		Description: "Same as -o json.",
+	},
+}
+
+// outputFormat returns the format a command was asked to render its result in with
+// the common flags, or else the default one
+func outputFormat(values Values, fallback string) string {
+	switch {
+	case values.Bool(FLAG_JSON):
+		return render.FORMAT_JSON
+	case values.Has(FLAG_OUTPUT):
+		return values.String(FLAG_OUTPUT)
+	case values.Has(FLAG_OUTPUT_LONG):
+		return values.String(FLAG_OUTPUT_LONG)
+	}
+	return fallback
+}
+
+// ErrExit is returned by the exit command, for the Pokedex to close once its goodbye
+// is rendered
+var ErrExit = errors.New("exit")
+
+// ErrFailed is returned by commands that couldn't do what they were asked, like
+// inspecting a Pokémon that was never caught. Why is told in their messages.
+var ErrFailed = errors.New("failed")
+
+// failed tells the player why a command couldn't do what it was asked, and returns its
+// messages along with ErrFailed, for scripts to stop there
+func failed(out *Messages, format string, a ...any) (Result, error) {
+	reason := fmt.Sprintf(format, a...)
+	fmt.Fprintln(out, reason)
+	return out, fmt.Errorf("%w: %s", ErrFailed, reason)
+}
+
+// Config is the state a command keeps between calls, like the page of location areas
+// map is at. What a command is called with is never kept, see Values.
 type Config struct {
 	Next     string
 	Previous string
-	Params   []string
-}
-
-type Flag struct {
-	Name        string
-	Description string
 }
 
 type Command struct {
 	Name        string
 	Description string
+	Args        []Flag // positional arguments, in order
 	Flags       []Flag
 	Config      *Config
-	Command     func(*Config, *cache.Cache) error
+	Command     func(*Invocation, *Config, Values, *cache.Cache) (Result, error)
+	registry    *Registry // the registry the command belongs to
+}
+
+var routeFlags = []Flag{
+	{
+		Name:        FLAG_AVOID,
+		Value:       "tag,...",
+		Description: "Never take exits with these tags (surf, cut, bike, strength, gated).",
+	},
+	{
+		Name:        FLAG_COST,
+		Value:       "tag=n",
+		Description: "Sets the cost of taking exits with a tag (1 by default).",
+	},
 }
 
-func GetRegistry() map[string]Command {
+// pokemonArg is one of the player's Pokémon, by ID, nickname or species
+var pokemonArg = Flag{
+	Name:        ARG_POKEMON,
+	Value:       "id|pokemon",
+	Required:    true,
+	Description: "One of your Pokémon, by ID (#1), nickname or species.",
+}
+
+// NewRegistry returns the commands of the Pokedex, with their state from scratch. It
+// renders results as text to the standard output, and never asks the player anything
+// until SetInteractive.
+func NewRegistry() *Registry {
+	r := &Registry{
+		output: render.FORMAT_TEXT,
+		stdout: os.Stdout,
+	}
 	mapConfig := Config{
 		Next: api.ENDPOINT_LOCATION_AREA + api.PAGINATION,
 	}
-	return map[string]Command{
+	r.commands = map[string]Command{
+		CMD_REGIONS: {
+			Name:        "regions",
+			Description: "Shows the names of all the regions in the Pokemon world.",
+			Config: &Config{
+				Next: api.ENDPOINT_REGION,
+			},
+			Command: commandRegions,
+		},
+		CMD_LOCATIONS: {
+			Name:        "locations",
+			Description: "Shows the locations in a region, or in the current region.",
+			Args: []Flag{
+				{Name: ARG_REGION, Description: "The region, the current one by default."},
+			},
+			Config: &Config{
+				Next: api.ENDPOINT_REGION,
+			},
+			Command: commandLocations,
+		},
+		CMD_AREAS: {
+			Name:        "areas",
+			Description: "Shows the location areas in a location, or in the current location.",
+			Args: []Flag{
+				{Name: ARG_LOCATION, Description: "The location, the current one by default."},
+			},
+			Config: &Config{
+				Next: api.ENDPOINT_LOCATION,
+			},
+			Command: commandAreas,
+		},
+		CMD_LOOK: {
+			Name:        "look",
+			Description: "Shows the exits leading out of the current location area.",
+			Config:      &Config{},
+			Command:     commandLook,
+		},
+		CMD_GO: {
+			Name:        "go",
+			Description: "Moves to a neighbouring location area by direction (north, south...) or exit number.",
+			Args: []Flag{
+				{Name: ARG_DIRECTION, Value: "direction|n", Required: true, Description: "The direction of the exit, or its number as listed by look."},
+			},
+			Config:  &Config{},
+			Command: commandGo,
+		},
+		CMD_ROUTE: {
+			Name:        "route",
+			Description: "Shows the shortest path from the current location area to another one.",
+			Args: []Flag{
+				{Name: ARG_AREA, Required: true, Description: "The location area to go to."},
+			},
+			Flags:   routeFlags,
+			Config:  &Config{},
+			Command: commandRoute,
+		},
+		CMD_HUNT: {
+			Name:        "hunt",
+			Description: "Shows the shortest path to the nearest area where a Pokémon appears in your game version.",
+			Args: []Flag{
+				{Name: ARG_POKEMON, Required: true, Description: "The species to hunt."},
+			},
+			Flags: routeFlags,
+			Config: &Config{
+				Next: api.ENDPOINT_POKEMON,
+			},
+			Command: commandHunt,
+		},
+		CMD_VERSION: {
+			Name:        "version",
+			Description: "Shows or sets the game version used to look up encounters.",
+			Args: []Flag{
+				{Name: ARG_VERSION, Description: "The game version to set, like red or yellow."},
+			},
+			Config:  &Config{},
+			Command: commandVersion,
+		},
+		CMD_WHERE: {
+			Name:        "where",
+			Description: "Shows every location area where a Pokémon can be found, grouped by region.",
+			Args: []Flag{
+				{Name: ARG_POKEMON, Required: true, Description: "The species to look for."},
+			},
+			Config: &Config{
+				Next: api.ENDPOINT_POKEMON,
+			},
+			Command: commandWhere,
+		},
 		CMD_ENCOUNTER: {
 			Name:        "encounter",
 			Description: "Triggers a random Pokémon encounter in the currently visited area.",
@@ -66,7 +308,10 @@ func GetRegistry() map[string]Command {
 		},
 		CMD_VISIT: {
 			Name:        "visit",
-			Description: "Visits a location area.",
+			Description: "Teleports to any location area.",
+			Args: []Flag{
+				{Name: ARG_AREA, Required: true, Description: "The location area to teleport to."},
+			},
 			Config: &Config{
 				Next: api.ENDPOINT_LOCATION_AREA,
 			},
@@ -77,17 +322,17 @@ func GetRegistry() map[string]Command {
 			Description: "Shows the player's current location area.",
 			Flags: []Flag{
 				{
-					Name:        "-r",
+					Name:        FLAG_WHEREAMI_R,
+					Type:        TYPE_BOOL,
 					Description: "Shows the current region instead.",
 				},
 				{
-					Name:        "-l",
+					Name:        FLAG_WHEREAMI_L,
+					Type:        TYPE_BOOL,
 					Description: "Shows the current location instead.",
 				},
 			},
-			Config: &Config{
-				Params: []string{},
-			},
+			Config:  &Config{},
 			Command: commandWhereAmI,
 		},
 		CMD_LOAD: {
@@ -105,26 +350,209 @@ func GetRegistry() map[string]Command {
 		CMD_POKEDEX: {
 			Name:        "pokedex",
 			Description: "Show all Pokémon from the Pokedex.",
-			Config:      &Config{},
-			Command:     commandPokedex,
+			Args: []Flag{
+				{
+					Name:        ARG_QUERY,
+					Variadic:    true,
+					Description: "Lists your Pokémon matching filters like type:fire, stat.speed>90 or caught>2026-01-01, then sort:-weight and limit:10.",
+				},
+			},
+			Flags: []Flag{
+				{
+					Name:        FLAG_PROGRESS,
+					Type:        TYPE_BOOL,
+					Description: "Shows the seen and caught completion, or the species missing from the generation or region given as query.",
+				},
+				{
+					Name:        FLAG_SHINY,
+					Type:        TYPE_BOOL,
+					Description: "Lists your shiny Pokémon.",
+				},
+			},
+			Config:  &Config{},
+			Command: commandPokedex,
 		},
 		CMD_INSPECT: {
 			Name:        "inspect",
-			Description: "Inspect a Pokémon from the Pokedex.",
+			Description: "Inspect one of your Pokémon by ID or species.",
+			Args:        []Flag{pokemonArg},
 			Config:      &Config{},
 			Command:     commandInspect,
 		},
+		CMD_BAG: {
+			Name:        "bag",
+			Description: "Shows the items in your bag.",
+			Config:      &Config{},
+			Command:     commandBag,
+		},
+		CMD_FIGHT: {
+			Name:        "fight",
+			Description: "Attacks the wild Pokémon you are facing with a move of your lead Pokémon.",
+			Args: []Flag{
+				{Name: ARG_MOVE, Required: true, Description: "A move your lead Pokémon knows."},
+			},
+			Config: &Config{
+				Next: api.ENDPOINT_MOVE,
+			},
+			Command: commandFight,
+		},
+		CMD_LEAD: {
+			Name:        "lead",
+			Description: "Shows or sets the party Pokémon that fights wild Pokémon.",
+			Args: []Flag{
+				{Name: ARG_POKEMON, Value: "id|pokemon", Description: "The party Pokémon to lead with."},
+			},
+			Config:  &Config{},
+			Command: commandLead,
+		},
+		CMD_PARTY: {
+			Name:        "party",
+			Description: "Lists the Pokémon in your party.",
+			Config:      &Config{},
+			Command:     commandParty,
+		},
+		CMD_BOX: {
+			Name:        "box",
+			Description: "Lists your PC boxes, or the Pokémon in one of them.",
+			Args: []Flag{
+				{Name: ARG_BOX, Value: "n", Type: TYPE_INT, Description: "The number of the box."},
+			},
+			Flags: []Flag{
+				{
+					Name:        FLAG_NAME,
+					Variadic:    true,
+					Description: "Renames the box.",
+				},
+			},
+			Config:  &Config{},
+			Command: commandBox,
+		},
+		CMD_DEPOSIT: {
+			Name:        "deposit",
+			Description: "Moves a Pokémon from your party to the PC.",
+			Args:        []Flag{pokemonArg},
+			Config:      &Config{},
+			Command:     commandDeposit,
+		},
+		CMD_WITHDRAW: {
+			Name:        "withdraw",
+			Description: "Moves a Pokémon from the PC to your party.",
+			Args:        []Flag{pokemonArg},
+			Config:      &Config{},
+			Command:     commandWithdraw,
+		},
+		CMD_SWAP: {
+			Name:        "swap",
+			Description: "Swaps the places of two of your Pokémon, in the party or the PC.",
+			Args: []Flag{
+				pokemonArg,
+				{Name: ARG_OTHER, Value: "id|pokemon", Required: true, Description: "The Pokémon to swap places with."},
+			},
+			Config:  &Config{},
+			Command: commandSwap,
+		},
+		CMD_RELEASE: {
+			Name:        "release",
+			Description: "Sets one of your Pokémon free.",
+			Args:        []Flag{pokemonArg},
+			Config:      &Config{},
+			Command:     commandRelease,
+		},
+		CMD_USE: {
+			Name:        "use",
+			Description: "Uses an item from your bag on one of your Pokémon, like an evolution stone.",
+			Args: []Flag{
+				{Name: ARG_ITEM, Required: true, Description: "An item in your bag."},
+				pokemonArg,
+			},
+			Config:  &Config{},
+			Command: commandUse,
+		},
+		CMD_TRADE: {
+			Name:        "trade",
+			Description: "Trades one of your Pokémon away and back, which makes some species evolve.",
+			Args:        []Flag{pokemonArg},
+			Config:      &Config{},
+			Command:     commandTrade,
+		},
+		CMD_RENAME: {
+			Name:        "rename",
+			Description: "Gives one of your Pokémon a nickname, or removes it.",
+			Args: []Flag{
+				pokemonArg,
+				{Name: ARG_NICKNAME, Type: TYPE_TEXT, Description: "The new nickname, none to go back to the species name."},
+			},
+			Config:  &Config{},
+			Command: commandRename,
+		},
+		CMD_SHINY: {
+			Name:        "shiny",
+			Description: "Shows the odds of meeting a shiny Pokémon and how many you caught.",
+			Flags: []Flag{
+				{
+					Name:        FLAG_ODDS,
+					Value:       "n",
+					Type:        TYPE_INT,
+					Description: "Makes 1 in n wild Pokémon shiny.",
+				},
+			},
+			Config:  &Config{},
+			Command: commandShiny,
+		},
+		CMD_COMPARE: {
+			Name:        "compare",
+			Description: "Compares two Pokémon side by side, yours by ID, nickname or species, or any species.",
+			Args: []Flag{
+				{Name: ARG_POKEMON, Value: "id|pokemon", Required: true, Description: "One of your Pokémon, or any species."},
+				{Name: ARG_OTHER, Value: "id|pokemon", Required: true, Description: "The Pokémon to compare it with."},
+			},
+			Config:  &Config{},
+			Command: commandCompare,
+		},
+		CMD_TEAM: {
+			Name:        "team",
+			Description: "Shows the types your party hits super effectively, its shared weaknesses and missing resistances, and boxed Pokémon that close the gaps.",
+			Args: []Flag{
+				{Name: ARG_ACTION, Value: CMD_ANALYZE, Required: true, Choices: []string{CMD_ANALYZE}, Description: "What to do with your party."},
+			},
+			Config:  &Config{},
+			Command: commandTeam,
+		},
+		CMD_HEAL: {
+			Name:        "heal",
+			Description: "Restores the HP of all your Pokémon.",
+			Config:      &Config{},
+			Command:     commandHeal,
+		},
+		CMD_RUN: {
+			Name:        "run",
+			Description: "Runs away from the wild Pokémon you are facing.",
+			Config:      &Config{},
+			Command:     commandRun,
+		},
 		CMD_CATCH: {
 			Name:        "catch",
-			Description: "Try catch a Pokémon.",
-			Config: &Config{
-				Next: api.ENDPOINT_POKEMON,
+			Description: "Try catch the wild Pokémon you are facing.",
+			Args: []Flag{
+				{Name: ARG_POKEMON, Description: "The species of the wild Pokémon, to make sure what you throw at."},
+			},
+			Flags: []Flag{
+				{
+					Name:        FLAG_BALL,
+					Value:       "ball",
+					Default:     battle.BALL_POKE,
+					Description: "Throws a poke, great, ultra, master, net, dusk, quick or timer ball.",
+				},
 			},
+			Config:  &Config{},
 			Command: commandCatch,
 		},
 		CMD_EXPLORE: {
 			Name:        "explore",
 			Description: "Shows the names of all the Pokémons located in an area in the Pokemon world.",
+			Args: []Flag{
+				{Name: ARG_AREA, Description: "The location area, the current one by default."},
+			},
 			Config: &Config{
 				Next: api.ENDPOINT_LOCATION_AREA,
 			},
@@ -144,67 +572,93 @@ func GetRegistry() map[string]Command {
 		},
 		CMD_HELP: {
 			Name:        "help",
-			Description: "Shows the list of commands",
-			Command:     commandHelp,
+			Description: "Shows the list of commands, or how to use one of them.",
+			Args: []Flag{
+				{Name: ARG_COMMAND, Description: "The command to show the arguments and flags of."},
+			},
+			Config:  &Config{},
+			Command: commandHelp,
 		},
 		CMD_EXIT: {
 			Name:        "exit",
 			Description: "Exit the Pokedex CLI",
+			Config:      &Config{},
 			Command:     commandExit,
 		},
 	}
+	for name, command := range r.commands {
+		command.registry = r
+		r.commands[name] = command
+	}
+	return r
 }
 
-func commandExit(config *Config, c *cache.Cache) error {
-	defer os.Exit(0)
-	fmt.Println("Closing the Pokedex... Goodbye!")
-	return nil
+func commandExit(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	fmt.Fprintln(out, "Closing the Pokedex... Goodbye!")
+	return out, ErrExit
+}
+
+// commandHelpFor describes a command with the arguments and flags it declares
+func commandHelpFor(command Command) CommandHelp {
+	return CommandHelp{
+		Name:        command.Name,
+		Usage:       command.Usage(),
+		Description: command.Description,
+		Args:        command.Args,
+		Flags:       append(append([]Flag{}, command.Flags...), commonFlags...),
+	}
 }
 
-func commandHelp(config *Config, c *cache.Cache) error {
-	registry := GetRegistry()
-	fmt.Println("Welcome to the Pokedex!")
-	fmt.Println("\nusage: <command>")
-	fmt.Printf("\nThese are common Pokedex commands used in various situations:\n\n")
-	for _, data := range registry {
-		fmt.Printf("    %s \t%s\n", data.Name, data.Description)
-		for _, flag := range data.Flags {
-			fmt.Printf("    \t\t%s \t%s\n", flag.Name, flag.Description)
+func commandHelp(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	if args.Has(ARG_COMMAND) {
+		name := args.String(ARG_COMMAND)
+		command, ok := inv.Command(name)
+		if !ok {
+			return nil, inv.usageError("invalid %s %q, expected one of the commands listed by help", ARG_COMMAND, name)
 		}
+		return commandHelpFor(command), nil
 	}
-	return nil
+	help := Help{Commands: []CommandHelp{}, Flags: commonFlags}
+	for _, command := range inv.commands {
+		help.Commands = append(help.Commands, commandHelpFor(command))
+	}
+	sort.Slice(help.Commands, func(i, j int) bool {
+		return help.Commands[i].Name < help.Commands[j].Name
+	})
+	return help, nil
 }
 
-func commandMapForward(config *Config, c *cache.Cache) error {
+func commandMapForward(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
 	if config.Next == "" {
-		return fmt.Errorf("error: cant't map forward")
+		return nil, fmt.Errorf("error: cant't map forward")
 	}
 	return Map(config, config.Next, CMD_MAP, c)
 }
 
-func commandMapBack(config *Config, c *cache.Cache) error {
+func commandMapBack(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
 	if config.Previous == "" {
-		return fmt.Errorf("error: cant't map back")
+		return nil, fmt.Errorf("error: cant't map back")
 	}
 	return Map(config, config.Previous, CMD_MAPB, c)
 }
 
-func Map(config *Config, url string, cmd string, c *cache.Cache) error {
+func Map(config *Config, url string, cmd string, c *cache.Cache) (Result, error) {
 	var pokeLocationArea api.LocationAreas
 	cachedEntry, ok := c.Get(cmd)
 	if ok {
 		if err := json.Unmarshal(cachedEntry.Val, &pokeLocationArea); err != nil {
-			return fmt.Errorf("error: unmarshal operation failed from cached entry: %w", err)
+			return nil, fmt.Errorf("error: unmarshal operation failed from cached entry: %w", err)
 		}
 	} else {
 		p, err := api.GetLocationAreas(url)
 		if err != nil {
-			return fmt.Errorf("error: failed getting location areas (%w)", err)
+			return nil, fmt.Errorf("error: failed getting location areas (%w)", err)
 		}
 		// cache data
 		data, err := json.Marshal(p)
 		if err != nil {
-			return fmt.Errorf("error: marshal operation failed: %w", err)
+			return nil, fmt.Errorf("error: marshal operation failed: %w", err)
 		}
 		c.Add(cmd, data)
 
@@ -213,239 +667,1914 @@ func Map(config *Config, url string, cmd string, c *cache.Cache) error {
 		config.Next = pokeLocationArea.Next
 		config.Previous = pokeLocationArea.Previous
 	}
-	// Print results
 	names := make([]string, len(pokeLocationArea.Results))
 	for i, result := range pokeLocationArea.Results {
 		names[i] = result.Name
 	}
-	terminal.PrettyPrint(names)
-	return nil
+	return AreaList{Areas: names, Next: pokeLocationArea.Next, Previous: pokeLocationArea.Previous}, nil
 }
 
-func commandExplore(config *Config, c *cache.Cache) error {
+func commandExplore(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
 	var pokemons []pokedex.Pokemon
-	var locationAreaName string
-	if len(config.Params) == 0 {
+	locationAreaName := args.String(ARG_AREA)
+	if locationAreaName == "" {
 		locationAreaName = c.Pokedex.CurrentLocation.LocationArea
-	} else {
-		locationAreaName = config.Params[0]
 	}
 	fullCommand := CMD_EXPLORE + locationAreaName
 	cachedEntry, ok := c.Get(fullCommand)
 	if ok {
 		if err := json.Unmarshal(cachedEntry.Val, &pokemons); err != nil {
-			return fmt.Errorf("error: unmarshal operation failed from cached entry: %w", err)
+			return nil, fmt.Errorf("error: unmarshal operation failed from cached entry: %w", err)
 		}
 	} else {
 		fullUrl := config.Next + locationAreaName
 		p, err := api.GetPokemonsInLocationArea(fullUrl)
 		if err != nil {
-			return fmt.Errorf("error: failed getting pokemons in location area (%w)", err)
+			return nil, fmt.Errorf("error: failed getting pokemons in location area (%w)", err)
 		}
 		// cache data
 		pokemonsJsonData, err := json.Marshal(p)
 		if err != nil {
-			return fmt.Errorf("error: marshal operation failed: %w", err)
+			return nil, fmt.Errorf("error: marshal operation failed: %w", err)
 		}
 		c.Add(fullCommand, pokemonsJsonData)
 
 		pokemons = p
 	}
-	// Print results
 	names := make([]string, len(pokemons))
 	for i, pokemon := range pokemons {
 		names[i] = pokemon.Name
+		c.Pokedex.MarkSeen(pokemon, locationAreaName)
 	}
-	terminal.PrettyPrint(names)
-	return nil
+	return AreaPokemon{Area: locationAreaName, Pokemon: names}, nil
 }
 
-func commandCatch(config *Config, c *cache.Cache) error {
-	var pokemon pokedex.Pokemon
+// ballName returns the name of the ball given to --ball, whose "-ball" suffix may be
+// omitted
+func ballName(ball string) string {
+	if !strings.HasSuffix(ball, "-ball") {
+		ball += "-ball"
+	}
+	return ball
+}
 
-	var pokemonName string
-	if len(config.Params) == 0 {
-		cachedEntry, ok := c.Get(CMD_ENCOUNTER)
-		if ok {
-			pokemonName = string(cachedEntry.Val)
-		} else {
-			fmt.Print("Nothing to catch!\n")
-			return nil
-		}
-	} else {
-		pokemonName = config.Params[0]
+func commandCatch(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	ball := ballName(args.String(FLAG_BALL))
+	out := inv.messages()
+	encounter := c.Encounter
+	if encounter == nil {
+		return failed(out, "Nothing to catch!")
 	}
-	fullCommand := CMD_EXPLORE + pokemonName
+	pokemon := encounter.Pokemon
+	if name := args.String(ARG_POKEMON); name != "" && name != pokemon.Name {
+		return failed(out, "There is no wild %s here, only %s!", name, pokemon.Name)
+	}
+	types := make([]string, len(pokemon.Types))
+	for i, pokemonType := range pokemon.Types {
+		types[i] = pokemonType.Type.Name
+	}
+	ballModifier, err := battle.BallModifier(ball, battle.BallContext{
+		Turn:  encounter.Turn,
+		Time:  time.Now(),
+		Types: types,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c.Pokedex.Full() {
+		return failed(out, "Your party and PC are full! Release some Pokémon first.")
+	}
+	curve, err := getGrowthCurve(pokemon, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Pokedex.UseItem(ball); err != nil {
+		return failed(out, "You have no %ss left!", ball)
+	}
+	shakes := encounter.Capture(ballModifier).Shakes(rand.Intn)
 
-	cachedEntry, ok := c.Get(fullCommand)
-	if ok {
-		if err := json.Unmarshal(cachedEntry.Val, &pokemon); err != nil {
-			return fmt.Errorf("error: unmarshal operation failed from cached entry: %w", err)
+	fmt.Fprintf(out, "Throwing a %s at %s! ", ball, pokemon.Name)
+	out.Flush()
+	// The ball wobbles once per passed shake check, a sec apart to add excitement for
+	// the player watching. Scripts and other formats get the shakes straight away.
+	wait := func() {}
+	if inv.live != nil && inv.prompt != nil {
+		duration, _ := time.ParseDuration("1s")
+		ticker := time.NewTicker(duration)
+		defer ticker.Stop()
+		wait = func() { <-ticker.C }
+	}
+	for i := 1; i <= min(shakes, battle.SHAKE_CHECKS-1); i++ {
+		wait()
+		fmt.Fprintf(out, "%d... ", i)
+		out.Flush()
+	}
+	wait()
+	if battle.Caught(shakes) {
+		// a Pokémon seen in an encounter is only caught now
+		entry, ok := c.Pokedex.Get(pokemon.Name)
+		newSpecies := !ok || !entry.Caught()
+		if newSpecies {
+			c.Pokedex.Add(pokemon)
 		}
-	} else {
-		fullUrl := config.Next + pokemonName
-		p, err := api.GetPokemon(fullUrl)
-		if err != nil {
-			return fmt.Errorf("error: failed getting pokemons in location area (%w)", err)
+		wild := encounter.Wild
+		wild.CaughtAt = time.Now()
+		wild.CaughtLocation = c.Pokedex.CurrentLocation
+		wild.Ball = ball
+		wild.XP = curve.Experience(wild.Level)
+		lead, hasLead := c.Pokedex.Lead()
+		id := c.Pokedex.AddIndividual(wild)
+		if wild.Shiny {
+			fmt.Fprintf(out, "caught!\n✨ The shiny %s was caught! (#%d)\n", pokemon.Name, id)
+		} else {
+			fmt.Fprintf(out, "caught!\n%s was caught! (#%d)\n", pokemon.Name, id)
 		}
-		// cache data
-		pokemonJsonData, err := json.Marshal(p)
+		c.Encounter = nil
+		box, err := c.Pokedex.Store(id)
 		if err != nil {
-			return fmt.Errorf("error: marshal operation failed: %w", err)
-		}
-		c.Add(fullCommand, pokemonJsonData)
-
-		pokemon = p
-	}
-	fmt.Printf("Throwing a Pokeball at %s!", pokemon.Name)
-	// We generate ellipsis every sec to add excitement
-	duration, _ := time.ParseDuration("1s")
-	ticker := time.NewTicker(duration)
-	defer ticker.Stop()
-	done := make(chan bool)
-	tickerCount := 3
-	go func() {
-		for range ticker.C {
-			fmt.Printf(".")
-			<-ticker.C
-			tickerCount--
-			if tickerCount == 0 {
-				done <- true
-				return
+			return out, err
+		}
+		// completing the national dex earns the Shiny Charm
+		if newSpecies {
+			if err := awardShinyCharm(out, c); err != nil {
+				return out, err
 			}
 		}
-	}()
-	<-done
-	fmt.Println()
-	// TODO: Add a formula that generates a decent Capture Probability
-	if rand.Float64() > 0.5 {
-		fmt.Printf("%s was caught!\n", pokemon.Name)
-		if _, ok := c.Pokedex.Get(pokemon.Name); !ok {
-			c.Pokedex.Add(pokemon)
+		if err := askNickname(wild, out); err != nil {
+			return out, err
 		}
-	} else {
-		fmt.Printf("%s escaped!\n", pokemon.Name)
+		if box != pokedex.PARTY {
+			fmt.Fprintf(out, "Your party is full, %s was sent to the PC (box %d).\n", pokemon.Name, box)
+		}
+		if hasLead && lead.HP > 0 {
+			return out, gainExperience(lead, encounter.Experience(), out, c)
+		}
+		return out, nil
 	}
-	return nil
+	fmt.Fprintf(out, "\n%s escaped!\n", pokemon.Name)
+	if encounter.Flees(rand.Intn) {
+		fmt.Fprintf(out, "The wild %s fled!\n", pokemon.Name)
+		c.Encounter = nil
+		return out, nil
+	}
+	encounter.Turn++
+	return out, nil
 }
 
-func commandInspect(config *Config, c *cache.Cache) error {
-	pokedexEntry, ok := c.Pokedex.Get(config.Params[0])
-	if !ok {
-		fmt.Println("You have not caught that pokemon")
+// ask flushes the messages before asking the player a question. Without a prompter
+// the answer is empty, which takes the default: no nickname, no evolution and no move
+// forgotten.
+func ask(out *Messages, question string) (string, error) {
+	out.Flush()
+	if out.prompt == nil {
+		return "", nil
+	}
+	return out.prompt(question)
+}
+
+// askNickname offers to nickname a Pokémon until the player gives a valid nickname or
+// none at all
+func askNickname(individual *pokedex.Individual, out *Messages) error {
+	for {
+		answer, err := ask(out, fmt.Sprintf("Give a nickname to the caught %s? (enter to skip) ", individual.Species))
+		if err != nil {
+			return err
+		}
+		if answer == "" {
+			return nil
+		}
+		if err := pokedex.ValidateNickname(answer); err != nil {
+			fmt.Fprintf(out, "Invalid nickname: %v\n", err)
+			continue
+		}
+		individual.Nickname = answer
 		return nil
 	}
-	fmt.Printf("Name: %s\n", pokedexEntry.Pokemon.Name)
-	fmt.Printf("Height: %v\n", pokedexEntry.Pokemon.Height)
-	fmt.Printf("Weight: %v\n", pokedexEntry.Pokemon.Weight)
-	fmt.Printf("Stats:\n")
-	for _, stat := range pokedexEntry.Pokemon.Stats {
-		fmt.Printf("  -%s: %v\n", stat.Stat.Name, stat.Base)
+}
+
+func commandRename(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	individual, ok := c.Pokedex.Find(args.String(ARG_POKEMON))
+	if !ok {
+		return failed(out, "You have not caught that pokemon")
+	}
+	if !args.Has(ARG_NICKNAME) {
+		individual.Nickname = ""
+		fmt.Fprintf(out, "#%d is called %s again.\n", individual.ID, individual.Species)
+		return out, nil
 	}
-	fmt.Printf("Types:\n")
-	for _, pokemonType := range pokedexEntry.Pokemon.Types {
-		fmt.Printf("  - %s\n", pokemonType.Type.Name)
+	nickname := args.String(ARG_NICKNAME)
+	if err := pokedex.ValidateNickname(nickname); err != nil {
+		return failed(out, "Invalid nickname: %v", err)
 	}
-	return nil
+	individual.Nickname = nickname
+	fmt.Fprintf(out, "#%d %s is now called %s.\n", individual.ID, individual.Species, nickname)
+	return out, nil
 }
 
-func commandPokedex(config *Config, c *cache.Cache) error {
-	pokemonNames := c.Pokedex.GetAll()
-	if len(pokemonNames) == 0 {
-		fmt.Println("your Pokedex is empty... Try catch some Pokémons first!")
-	} else {
-		fmt.Println("Your Pokedex:")
-		terminal.PrettyPrint(pokemonNames)
+// pokemonCommands take one of the player's Pokémon as argument
+var pokemonCommands = []string{
+	CMD_INSPECT, CMD_LEAD, CMD_DEPOSIT, CMD_WITHDRAW, CMD_SWAP, CMD_RELEASE, CMD_RENAME, CMD_USE, CMD_TRADE, CMD_COMPARE,
+}
+
+// Completions returns the candidates to complete the last of the words typed so far:
+// command names, or the IDs, nicknames and species of the player's Pokémon
+func (r *Registry) Completions(words []string, c *cache.Cache) []string {
+	if len(words) <= 1 {
+		names := []string{}
+		for name := range r.commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
 	}
-	return nil
+	if !slices.Contains(pokemonCommands, words[0]) {
+		return nil
+	}
+	if words[0] == CMD_USE && len(words) == 2 {
+		c.Pokedex.Mu.RLock()
+		defer c.Pokedex.Mu.RUnlock()
+		items := []string{}
+		for item, count := range c.Pokedex.Bag {
+			if count > 0 {
+				items = append(items, item)
+			}
+		}
+		sort.Strings(items)
+		return items
+	}
+	candidates := []string{}
+	for _, individual := range c.Pokedex.Individuals() {
+		candidates = append(candidates, fmt.Sprintf("#%d", individual.ID))
+		if individual.Nickname != "" {
+			candidates = append(candidates, individual.Nickname)
+		}
+		if !slices.Contains(candidates, individual.Species) {
+			candidates = append(candidates, individual.Species)
+		}
+	}
+	return candidates
 }
 
-func commandSave(config *Config, c *cache.Cache) error {
-	if err := session.Save(c.Pokedex, session.DATA_DIR); err != nil {
-		return fmt.Errorf("error saving pokedex %w", err)
+func commandRun(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	if c.Encounter == nil {
+		return failed(out, "There is nothing to run from!")
 	}
-	return nil
+	fmt.Fprintln(out, "Got away safely!")
+	c.Encounter = nil
+	return out, nil
 }
 
-func commandLoad(config *Config, c *cache.Cache) error {
-	pokedex, err := session.Load(session.DATA_DIR)
+func commandInspect(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	individual, pokemon, ok := findOwned(args.String(ARG_POKEMON), c)
+	if !ok {
+		out := inv.messages()
+		return failed(out, "You have not caught that pokemon")
+	}
+	if err := fillGender(individual, pokemon, c); err != nil {
+		return nil, err
+	}
+	nature, err := getNature(individual.Nature, c)
 	if err != nil {
-		return fmt.Errorf("error loading game %w", err)
+		return nil, err
 	}
-	c.Pokedex = pokedex
-	return nil
+	details := PokemonDetails{
+		ID:         individual.ID,
+		Name:       individual.Name(),
+		Species:    pokemon.Name,
+		Shiny:      individual.Shiny,
+		Sprite:     pokemon.Sprite(individual.Shiny),
+		Level:      individual.Level,
+		XP:         individual.XP,
+		HP:         individual.HP,
+		MaxHP:      battle.MaxHP(pokemon, individual),
+		Status:     statusLabel(individual.Status),
+		Nature:     NatureDetails{Name: individual.Nature, Increased: nature.Increased, Decreased: nature.Decreased},
+		Gender:     individual.Gender,
+		Ability:    individual.Ability,
+		Friendship: individual.Friendship,
+		Height:     pokemon.Height,
+		Weight:     pokemon.Weight,
+		Stats:      []StatDetails{},
+		Types:      []string{},
+		Moves:      append([]string{}, individual.Moves...),
+		CaughtAt:   individual.CaughtAt,
+		CaughtIn:   individual.CaughtLocation.LocationArea,
+		Ball:       individual.Ball,
+	}
+	stats := battle.Stats(pokemon, individual, nature)
+	for _, stat := range pokemon.Stats {
+		name := stat.Stat.Name
+		detail := StatDetails{
+			Stat:  name,
+			Base:  stat.Base,
+			IV:    individual.IVs.Get(name),
+			EV:    individual.EVs.Get(name),
+			Final: stats.Get(name),
+		}
+		switch nature.Modifier(name) {
+		case 100 + pokedex.NATURE_BOOST:
+			detail.Nature = "+"
+		case 100 - pokedex.NATURE_BOOST:
+			detail.Nature = "-"
+		}
+		details.Stats = append(details.Stats, detail)
+	}
+	for _, pokemonType := range pokemon.Types {
+		details.Types = append(details.Types, pokemonType.Type.Name)
+	}
+	return details, nil
+}
+
+// ComparedPokemon is one side of a comparison: one of your Pokémon, or a species
+type ComparedPokemon struct {
+	Name      string   `json:"name"`
+	Species   string   `json:"species"`
+	Owned     bool     `json:"owned"`
+	Types     []string `json:"types"`
+	Height    int      `json:"height"`
+	Weight    int      `json:"weight"`
+	Abilities []string `json:"abilities"`
+}
+
+// StatComparison holds the base stat of both Pokémon, and how much higher the second's is
+type StatComparison struct {
+	Stat   string `json:"stat"`
+	Values [2]int `json:"values"`
+	Delta  int    `json:"delta"`
 }
 
-func commandWhereAmI(config *Config, c *cache.Cache) error {
-	locationArea := c.Pokedex.CurrentLocation.LocationArea
-	if len(config.Params) > 0 {
-		flag := config.Params[0]
-		switch flag {
-		case FLAG_WHEREAMI_R:
-			fmt.Printf("%s\n", c.Pokedex.CurrentLocation.Region)
-		case FLAG_WHEREAMI_L:
-			fmt.Printf("%s\n", c.Pokedex.CurrentLocation.Location)
-		default:
-			fmt.Printf("%s\n", locationArea)
+// Comparison sets two Pokémon side by side. Effectiveness holds the multiplier of each
+// of the first Pokémon's types against the second, and the other way around.
+type Comparison struct {
+	Pokemon       [2]ComparedPokemon    `json:"pokemon"`
+	Stats         []StatComparison      `json:"stats"`
+	Effectiveness [2]map[string]float64 `json:"effectiveness"`
+}
+
+func (comparison Comparison) Text(w io.Writer) error {
+	a, b := comparison.Pokemon[0], comparison.Pokemon[1]
+	terminal.FprintTable(w, []string{"", a.Name, b.Name}, [][]string{
+		{"Types", strings.Join(a.Types, "/"), strings.Join(b.Types, "/")},
+		{"Height", strconv.Itoa(a.Height), strconv.Itoa(b.Height)},
+		{"Weight", strconv.Itoa(a.Weight), strconv.Itoa(b.Weight)},
+		{"Abilities", strings.Join(a.Abilities, ", "), strings.Join(b.Abilities, ", ")},
+	})
+	fmt.Fprintln(w)
+	rows := make([][]string, len(comparison.Stats))
+	for i, stat := range comparison.Stats {
+		rows[i] = []string{stat.Stat, strconv.Itoa(stat.Values[0]), "", strconv.Itoa(stat.Values[1]), "", fmt.Sprintf("%+d", stat.Delta)}
+		if stat.Stat != "total" {
+			rows[i][2] = terminal.Bar(stat.Values[0], MAX_BASE_STAT, STAT_BAR_WIDTH)
+			rows[i][4] = terminal.Bar(stat.Values[1], MAX_BASE_STAT, STAT_BAR_WIDTH)
+		}
+	}
+	terminal.FprintTable(w, []string{"STAT", a.Name, "", b.Name, "", "DELTA"}, rows)
+	fmt.Fprintln(w)
+	for i, pair := range [2][2]ComparedPokemon{{a, b}, {b, a}} {
+		for _, t := range pair[0].Types {
+			fmt.Fprintf(w, "%s's %s moves hit %s x%s\n", pair[0].Name, t, pair[1].Name, strconv.FormatFloat(comparison.Effectiveness[i][t], 'g', -1, 64))
 		}
-		return nil
 	}
-	fmt.Printf("%s\n", locationArea)
 	return nil
 }
 
-func commandVisit(config *Config, c *cache.Cache) error {
-	if len(config.Params) == 0 {
-		return fmt.Errorf("received no argument")
+func (comparison Comparison) Table() ([]string, [][]string) {
+	rows := make([][]string, len(comparison.Stats))
+	for i, stat := range comparison.Stats {
+		rows[i] = []string{stat.Stat, strconv.Itoa(stat.Values[0]), strconv.Itoa(stat.Values[1]), strconv.Itoa(stat.Delta)}
 	}
-	var LocationArea api.LocationArea
-	locaAreaName := config.Params[0]
-	fullCommand := CMD_VISIT + " " + locaAreaName
-	cachedEntry, ok := c.Get(fullCommand)
-	if ok {
-		if err := json.Unmarshal(cachedEntry.Val, &LocationArea); err != nil {
-			return fmt.Errorf("failed to unmarshal location area: %w", err)
+	return []string{"STAT", comparison.Pokemon[0].Name, comparison.Pokemon[1].Name, "DELTA"}, rows
+}
+
+// comparePokemon compares the base stats, types, size and abilities of two Pokémon
+func comparePokemon(a, b ComparedPokemon, pokemonA, pokemonB pokedex.Pokemon) Comparison {
+	comparison := Comparison{Pokemon: [2]ComparedPokemon{a, b}}
+	total := StatComparison{Stat: "total"}
+	for _, stat := range pokemonA.Stats {
+		values := [2]int{stat.Base, pokemonB.BaseStat(stat.Stat.Name)}
+		comparison.Stats = append(comparison.Stats, StatComparison{Stat: stat.Stat.Name, Values: values, Delta: values[1] - values[0]})
+		total.Values[0] += values[0]
+	}
+	for _, stat := range pokemonB.Stats {
+		total.Values[1] += stat.Base
+	}
+	total.Delta = total.Values[1] - total.Values[0]
+	comparison.Stats = append(comparison.Stats, total)
+	for i, pair := range [2][2]ComparedPokemon{{a, b}, {b, a}} {
+		comparison.Effectiveness[i] = map[string]float64{}
+		for _, t := range pair[0].Types {
+			comparison.Effectiveness[i][t] = battle.Effectiveness(t, pair[1].Types)
 		}
-		c.Pokedex.CurrentLocation.LocationArea = LocationArea.Name
-		c.Pokedex.CurrentLocation.Location = LocationArea.Location.Name
-	} else {
-		fullUrl := config.Next + locaAreaName
-		locationArea, err := api.GetLocationArea(fullUrl)
-		if err != nil {
-			return fmt.Errorf("failed to retrieve location area: %w", err)
+	}
+	return comparison
+}
+
+// getCompared returns one of your Pokémon by ID, nickname or species, or else any species
+func getCompared(query string, c *cache.Cache) (ComparedPokemon, pokedex.Pokemon, error) {
+	individual, pokemon, owned := findOwned(query, c)
+	if !owned {
+		var err error
+		if pokemon, err = getPokemon(query, c); err != nil {
+			return ComparedPokemon{}, pokemon, err
 		}
-		data, err := json.Marshal(locationArea)
-		if err != nil {
-			return fmt.Errorf("failed to marshal location area: %w", err)
+	}
+	compared := ComparedPokemon{
+		Name:      pokemon.Name,
+		Species:   pokemon.Name,
+		Owned:     owned,
+		Types:     []string{},
+		Height:    pokemon.Height,
+		Weight:    pokemon.Weight,
+		Abilities: []string{},
+	}
+	if owned {
+		compared.Name = fmt.Sprintf("%s #%d", individual.Name(), individual.ID)
+	}
+	for _, t := range pokemon.Types {
+		compared.Types = append(compared.Types, t.Type.Name)
+	}
+	for _, ability := range pokemon.Abilities {
+		label := ability.Ability.Name
+		if ability.IsHidden {
+			label += " (hidden)"
 		}
-		c.Add(fullCommand, data)
-		c.Pokedex.CurrentLocation.LocationArea = locationArea.Name
-		c.Pokedex.CurrentLocation.Location = locationArea.Location.Name
+		compared.Abilities = append(compared.Abilities, label)
 	}
-	return nil
+	return compared, pokemon, nil
 }
 
-func commandEncounter(config *Config, c *cache.Cache) error {
-	fullEndpoint := config.Next + c.Pokedex.CurrentLocation.LocationArea
-	pokemonEncounters, err := api.GetPokemonEncounters(fullEndpoint)
+func commandCompare(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	a, pokemonA, err := getCompared(args.String(ARG_POKEMON), c)
 	if err != nil {
-		return fmt.Errorf("failed to get pokemon encounters: %w", err)
+		return nil, err
 	}
-	// roulette wheel selection
-	cumulativeWeights := 0
-	for _, encounter := range pokemonEncounters {
-		cumulativeWeights += encounter.Chance
+	b, pokemonB, err := getCompared(args.String(ARG_OTHER), c)
+	if err != nil {
+		return nil, err
 	}
-	pick := rand.Intn(cumulativeWeights)
-	cumulativeWeights = 0
-	for _, encounter := range pokemonEncounters {
-		if pick >= cumulativeWeights && pick <= cumulativeWeights+encounter.Chance {
-			fmt.Printf("You encountered a %s!\n", encounter.Name)
-			// cache encounter
-			c.Add(CMD_ENCOUNTER, []byte(encounter.Name))
-			break
-		}
-		cumulativeWeights += encounter.Chance - 1
+	return comparePokemon(a, b, pokemonA, pokemonB), nil
+}
+
+func commandPokedex(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	query := args.Strings(ARG_QUERY)
+	var result Result
+	var err error
+	switch {
+	case args.Bool(FLAG_PROGRESS):
+		result, err = pokedexProgress(inv.command, query, c)
+	case args.Bool(FLAG_SHINY):
+		result = pokedexShiny(c)
+	case len(query) > 0:
+		result, err = pokedexQuery(query, c)
+	default:
+		pokemonNames := c.Pokedex.GetAll()
+		sort.Strings(pokemonNames)
+		result = SpeciesList{Species: pokemonNames}
 	}
-	return nil
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// pokedexQuery lists the Pokémon you own that match a query, like
+// `type:fire stat.speed>90 sort:-weight limit:10`
+func pokedexQuery(terms []string, c *cache.Cache) (PokemonList, error) {
+	list := PokemonList{Title: "Your Pokémon", Empty: "None of your Pokémon match.", Pokemon: []PokemonSummary{}}
+	q, err := query.Parse(terms)
+	if err != nil {
+		return list, err
+	}
+	records, err := individualRecords(c)
+	if err != nil {
+		return list, err
+	}
+	records, err = query.Apply(records, q)
+	if err != nil {
+		return list, err
+	}
+	for _, record := range records {
+		list.Pokemon = append(list.Pokemon, pokemonSummary(record.Individual, c))
+	}
+	return list, nil
+}
+
+// pokemonSummary sums up one of your Pokémon for lists
+func pokemonSummary(individual *pokedex.Individual, c *cache.Cache) PokemonSummary {
+	return PokemonSummary{
+		ID:       individual.ID,
+		Name:     individual.Name(),
+		Species:  individual.Species,
+		Level:    individual.Level,
+		Types:    pokemonTypes(individual, c),
+		Shiny:    individual.Shiny,
+		CaughtAt: individual.CaughtAt,
+		CaughtIn: individual.CaughtLocation.LocationArea,
+	}
+}
+
+// individualRecord is one of your Pokémon as queried by list commands, with its
+// species' data
+type individualRecord struct {
+	*pokedex.Individual
+	pokemon pokedex.Pokemon
+}
+
+// individualRecords returns the Pokémon you own, by ID
+func individualRecords(c *cache.Cache) ([]individualRecord, error) {
+	individuals := c.Pokedex.Individuals()
+	records := make([]individualRecord, len(individuals))
+	for i, individual := range individuals {
+		records[i] = individualRecord{Individual: individual}
+		if entry, ok := c.Pokedex.Get(individual.Species); ok {
+			records[i].pokemon = entry.Pokemon
+			if err := fillGender(individual, entry.Pokemon, c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return records, nil
+}
+
+// fillGender rolls the gender of a Pokémon from a save of an older version, which
+// didn't keep it, from the gender rate of its species
+func fillGender(individual *pokedex.Individual, pokemon pokedex.Pokemon, c *cache.Cache) error {
+	if individual.Gender != "" {
+		return nil
+	}
+	species, err := getPokemonSpecies(speciesEndpoint(pokemon), c)
+	if err != nil {
+		return err
+	}
+	individual.Gender = pokedex.RollGender(species.GenderRate, rand.Intn)
+	return nil
+}
+
+// Field returns the values of a queryable field. Stats are the species' base stats.
+func (r individualRecord) Field(name string) ([]string, bool) {
+	if stat, ok := strings.CutPrefix(name, FIELD_STAT); ok {
+		for _, s := range r.pokemon.Stats {
+			if s.Stat.Name == stat {
+				return []string{strconv.Itoa(s.Base)}, true
+			}
+		}
+		return nil, false
+	}
+	switch name {
+	case query.NAME_FIELD:
+		return []string{r.Name(), r.Species}, true
+	case FIELD_SPECIES:
+		return []string{r.Species}, true
+	case FIELD_NICKNAME:
+		return []string{r.Nickname}, true
+	case FIELD_ID:
+		return []string{strconv.Itoa(r.ID)}, true
+	case FIELD_TYPE:
+		types := make([]string, len(r.pokemon.Types))
+		for i, t := range r.pokemon.Types {
+			types[i] = t.Type.Name
+		}
+		return types, true
+	case FIELD_LEVEL:
+		return []string{strconv.Itoa(r.Level)}, true
+	case FIELD_XP:
+		return []string{strconv.Itoa(r.XP)}, true
+	case FIELD_HEIGHT:
+		return []string{strconv.Itoa(r.pokemon.Height)}, true
+	case FIELD_WEIGHT:
+		return []string{strconv.Itoa(r.pokemon.Weight)}, true
+	case FIELD_CAUGHT:
+		return []string{r.CaughtAt.Format(time.RFC3339)}, true
+	case FIELD_LOCATION:
+		location := r.CaughtLocation
+		return []string{location.LocationArea, location.Location, location.Region}, true
+	case FIELD_BALL:
+		return []string{r.Ball}, true
+	case FIELD_SHINY:
+		return []string{strconv.FormatBool(r.Shiny)}, true
+	case FIELD_GENDER:
+		return []string{r.Gender}, true
+	case FIELD_NATURE:
+		return []string{r.Nature}, true
+	case FIELD_ABILITY:
+		return []string{r.Ability}, true
+	}
+	return nil, false
+}
+
+// dexProgress counts the seen and caught species of a dex
+func dexProgress(name string, species []string, c *cache.Cache) DexCompletion {
+	seen, caught := c.Pokedex.Progress(species)
+	return DexCompletion{Dex: name, Seen: seen, Caught: caught, Total: len(species)}
+}
+
+// pokedexProgress shows the completion of the national dex and of each generation,
+// or the missing species of one generation or region
+func pokedexProgress(command Command, params []string, c *cache.Cache) (Result, error) {
+	generationList, err := getCached(CMD_POKEDEX+" "+api.ENDPOINT_GENERATION, c, func() (api.NamedResources, error) {
+		return api.GetGenerations(api.ENDPOINT_GENERATION)
+	})
+	if err != nil {
+		return nil, err
+	}
+	generations := make([]api.Generation, len(generationList.Results))
+	for i, result := range generationList.Results {
+		generations[i], err = getCached(CMD_POKEDEX+" "+result.URL, c, func() (api.Generation, error) {
+			return api.GetGeneration(result.URL)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	speciesNames := func(generation api.Generation) []string {
+		names := make([]string, len(generation.PokemonSpecies))
+		for i, species := range generation.PokemonSpecies {
+			names[i] = species.Name
+		}
+		return names
+	}
+
+	if len(params) > 0 {
+		for _, generation := range generations {
+			if params[0] != generation.Name && params[0] != generation.MainRegion.Name {
+				continue
+			}
+			missing, seenIn := c.Pokedex.Missing(speciesNames(generation))
+			sort.Strings(missing)
+			result := MissingSpecies{Generation: generation.Name, Region: generation.MainRegion.Name, Missing: []MissingEntry{}}
+			for _, name := range missing {
+				_, seen := seenIn[name]
+				result.Missing = append(result.Missing, MissingEntry{Species: name, Seen: seen, FoundIn: foundIn(name, c)})
+			}
+			return result, nil
+		}
+		return nil, command.usageError("unknown generation or region %q", params[0])
+	}
+
+	nationalSpecies, err := getNationalSpecies(c)
+	if err != nil {
+		return nil, err
+	}
+	progress := DexProgress{Dexes: []DexCompletion{dexProgress(api.NATIONAL_POKEDEX, nationalSpecies, c)}}
+	for _, generation := range generations {
+		name := fmt.Sprintf("%s (%s)", generation.Name, generation.MainRegion.Name)
+		progress.Dexes = append(progress.Dexes, dexProgress(name, speciesNames(generation), c))
+	}
+	return progress, nil
+}
+
+// foundIn returns the location areas a species can be encountered in, in the player's
+// version. It is empty for a species that isn't found in the wild, and nil when its
+// encounters can't be looked up.
+func foundIn(species string, c *cache.Cache) []string {
+	version := c.Pokedex.Version
+	if version == "" {
+		version = pokedex.STARTING_VERSION
+	}
+	encounters, err := getEncounterAreas(api.ENDPOINT_POKEMON+species+"/encounters", c)
+	if err != nil {
+		return nil
+	}
+	areas := []string{}
+	for _, summary := range summarizeEncounters(encounters) {
+		if summary.Version == version && !slices.Contains(areas, summary.Area) {
+			areas = append(areas, summary.Area)
+		}
+	}
+	return areas
+}
+
+// getNationalSpecies returns the species of the national dex, from cache when possible
+func getNationalSpecies(c *cache.Cache) ([]string, error) {
+	national, err := getCached(CMD_POKEDEX+" "+api.NATIONAL_POKEDEX, c, func() (api.Pokedex, error) {
+		return api.GetPokedex(api.ENDPOINT_POKEDEX + api.NATIONAL_POKEDEX)
+	})
+	if err != nil {
+		return nil, err
+	}
+	species := make([]string, len(national.PokemonEntries))
+	for i, entry := range national.PokemonEntries {
+		species[i] = entry.PokemonSpecies.Name
+	}
+	return species, nil
+}
+
+// awardShinyCharm gives the Shiny Charm to the player once every species of the
+// national dex is caught
+func awardShinyCharm(out *Messages, c *cache.Cache) error {
+	c.Pokedex.Mu.RLock()
+	charms := c.Pokedex.Bag[pokedex.SHINY_CHARM]
+	c.Pokedex.Mu.RUnlock()
+	if charms > 0 {
+		return nil
+	}
+	species, err := getNationalSpecies(c)
+	if err != nil {
+		return err
+	}
+	if _, caught := c.Pokedex.Progress(species); caught < len(species) {
+		return nil
+	}
+	c.Pokedex.Mu.Lock()
+	c.Pokedex.Bag[pokedex.SHINY_CHARM] = 1
+	c.Pokedex.Mu.Unlock()
+	fmt.Fprintln(out, "You caught them all! Professor Oak gave you the Shiny Charm.")
+	return nil
+}
+
+// pokedexShiny lists the player's shiny Pokémon
+func pokedexShiny(c *cache.Cache) PokemonList {
+	list := PokemonList{Title: "Your shiny Pokémon", Empty: "You have no shiny Pokémon yet... Keep hunting!", Pokemon: []PokemonSummary{}}
+	for _, individual := range c.Pokedex.Shinies() {
+		list.Pokemon = append(list.Pokemon, pokemonSummary(individual, c))
+	}
+	return list
+}
+
+func commandShiny(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	if args.Has(FLAG_ODDS) {
+		odds := args.Int(FLAG_ODDS)
+		if odds < 1 {
+			return nil, inv.usageError("invalid %s value %q, expected at least 1", FLAG_ODDS, args.String(FLAG_ODDS))
+		}
+		c.Pokedex.Mu.Lock()
+		c.Pokedex.ShinyOdds = odds
+		c.Pokedex.Mu.Unlock()
+	}
+	c.Pokedex.Mu.RLock()
+	odds := c.Pokedex.ShinyOdds
+	charm := c.Pokedex.Bag[pokedex.SHINY_CHARM] > 0
+	c.Pokedex.Mu.RUnlock()
+	if odds <= 0 {
+		odds = pokedex.SHINY_ODDS
+	}
+	return ShinyStatus{Odds: odds, ShinyCharm: charm, Caught: len(c.Pokedex.Shinies())}, nil
+}
+
+func commandSave(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	if err := session.Save(c.Pokedex, session.DATA_DIR); err != nil {
+		return nil, fmt.Errorf("error saving pokedex %w", err)
+	}
+	return nil, nil
+}
+
+func commandLoad(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	pokedex, err := session.Load(session.DATA_DIR)
+	if err != nil {
+		return nil, fmt.Errorf("error loading game %w", err)
+	}
+	c.Pokedex = pokedex
+	return nil, nil
+}
+
+func commandWhereAmI(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	current := c.Pokedex.CurrentLocation
+	return PlayerLocation{
+		Region:   current.Region,
+		Location: current.Location,
+		Area:     current.LocationArea,
+		region:   args.Bool(FLAG_WHEREAMI_R),
+		location: args.Bool(FLAG_WHEREAMI_L),
+	}, nil
+}
+
+func commandVisit(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	if out, err := inTheWay(inv, c); err != nil {
+		return out, err
+	}
+	locationArea, err := getLocationArea(config.Next+args.String(ARG_AREA), c)
+	if err != nil {
+		return nil, err
+	}
+	// resolve the region the area's location belongs to
+	location, err := getLocation(api.ENDPOINT_LOCATION+locationArea.Location.Name, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve region: %w", err)
+	}
+	c.Pokedex.CurrentLocation.LocationArea = locationArea.Name
+	c.Pokedex.CurrentLocation.Location = locationArea.Location.Name
+	c.Pokedex.CurrentLocation.Region = location.Region.Name
+	return nil, nil
+}
+
+func commandEncounter(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	if c.Encounter != nil {
+		return failed(out, "You are already facing a wild %s! Catch it or run.", c.Encounter.Pokemon.Name)
+	}
+	fullEndpoint := config.Next + c.Pokedex.CurrentLocation.LocationArea
+	pokemonEncounters, err := api.GetPokemonEncounters(fullEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pokemon encounters: %w", err)
+	}
+	if len(pokemonEncounters) == 0 {
+		return failed(out, "There are no wild Pokémon around here.")
+	}
+	// roulette wheel selection
+	cumulativeWeights := 0
+	for _, encounter := range pokemonEncounters {
+		cumulativeWeights += encounter.Chance
+	}
+	pick := rand.Intn(cumulativeWeights)
+	picked := pokemonEncounters[len(pokemonEncounters)-1]
+	cumulativeWeights = 0
+	for _, encounter := range pokemonEncounters {
+		if pick < cumulativeWeights+encounter.Chance {
+			picked = encounter
+			break
+		}
+		cumulativeWeights += encounter.Chance
+	}
+	pokemon, err := getPokemon(picked.Name, c)
+	if err != nil {
+		return nil, err
+	}
+	species, err := getPokemonSpecies(speciesEndpoint(pokemon), c)
+	if err != nil {
+		return nil, err
+	}
+	level := picked.MinLevel + rand.Intn(max(picked.MaxLevel-picked.MinLevel, 0)+1)
+	wild := pokedex.NewIndividual(pokemon, max(level, 1), species.GenderRate, rand.Intn)
+	wild.Friendship = species.BaseHappiness
+	wild.Shiny = c.Pokedex.RollShiny(rand.Intn)
+	c.Encounter = battle.NewEncounter(pokemon, wild, species.CaptureRate)
+	c.Pokedex.MarkSeen(pokemon, c.Pokedex.CurrentLocation.LocationArea)
+	if wild.Shiny {
+		fmt.Fprintf(out, "✨ A shiny wild %s (Lv. %d) appeared! ✨\n", pokemon.Name, wild.Level)
+		return out, nil
+	}
+	fmt.Fprintf(out, "A wild %s (Lv. %d) appeared!\n", pokemon.Name, wild.Level)
+	return out, nil
+}
+
+// getPokemon returns a Pokémon by name, from cache when possible
+func getPokemon(pokemonName string, c *cache.Cache) (pokedex.Pokemon, error) {
+	var pokemon pokedex.Pokemon
+	fullCommand := CMD_EXPLORE + pokemonName
+	cachedEntry, ok := c.Get(fullCommand)
+	if ok {
+		if err := json.Unmarshal(cachedEntry.Val, &pokemon); err != nil {
+			return pokemon, fmt.Errorf("error: unmarshal operation failed from cached entry: %w", err)
+		}
+		return pokemon, nil
+	}
+	pokemon, err := api.GetPokemon(api.ENDPOINT_POKEMON + pokemonName)
+	if err != nil {
+		return pokemon, fmt.Errorf("error: failed getting pokemon (%w)", err)
+	}
+	data, err := json.Marshal(pokemon)
+	if err != nil {
+		return pokemon, fmt.Errorf("error: marshal operation failed: %w", err)
+	}
+	c.Add(fullCommand, data)
+	return pokemon, nil
+}
+
+// speciesEndpoint returns the endpoint of the species a Pokémon belongs to
+func speciesEndpoint(pokemon pokedex.Pokemon) string {
+	if pokemon.Species.Url != "" {
+		return pokemon.Species.Url
+	}
+	return api.ENDPOINT_SPECIES + pokemon.Name
+}
+
+// getCached returns the cached value under a key, or fetches and caches it
+func getCached[T any](key string, c *cache.Cache, fetch func() (T, error)) (T, error) {
+	var val T
+	if cachedEntry, ok := c.Get(key); ok {
+		if err := json.Unmarshal(cachedEntry.Val, &val); err != nil {
+			return val, fmt.Errorf("failed to unmarshal cached entry: %w", err)
+		}
+		return val, nil
+	}
+	val, err := fetch()
+	if err != nil {
+		return val, err
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return val, fmt.Errorf("failed to marshal cached entry: %w", err)
+	}
+	c.Add(key, data)
+	return val, nil
+}
+
+// getLocationArea returns the location area at the given endpoint, from cache when possible
+func getLocationArea(endpoint string, c *cache.Cache) (api.LocationArea, error) {
+	return getCached(CMD_VISIT+" "+endpoint, c, func() (api.LocationArea, error) {
+		return api.GetLocationArea(endpoint)
+	})
+}
+
+// getPokemonSpecies returns the species at the given endpoint, from cache when possible
+func getPokemonSpecies(endpoint string, c *cache.Cache) (api.PokemonSpecies, error) {
+	return getCached(CMD_CATCH+" "+endpoint, c, func() (api.PokemonSpecies, error) {
+		return api.GetPokemonSpecies(endpoint)
+	})
+}
+
+// getLocation returns the location at the given endpoint, from cache when possible
+func getLocation(endpoint string, c *cache.Cache) (api.Location, error) {
+	return getCached(CMD_AREAS+" "+endpoint, c, func() (api.Location, error) {
+		return api.GetLocation(endpoint)
+	})
+}
+
+// getRegion returns the region at the given endpoint, from cache when possible
+func getRegion(endpoint string, c *cache.Cache) (api.Region, error) {
+	return getCached(CMD_LOCATIONS+" "+endpoint, c, func() (api.Region, error) {
+		return api.GetRegion(endpoint)
+	})
+}
+
+func commandRegions(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	var regions api.NamedResources
+	cachedEntry, ok := c.Get(CMD_REGIONS)
+	if ok {
+		if err := json.Unmarshal(cachedEntry.Val, &regions); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal regions: %w", err)
+		}
+	} else {
+		r, err := api.GetRegions(config.Next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve regions: %w", err)
+		}
+		data, err := json.Marshal(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal regions: %w", err)
+		}
+		c.Add(CMD_REGIONS, data)
+		regions = r
+	}
+	names := make([]string, len(regions.Results))
+	for i, result := range regions.Results {
+		names[i] = result.Name
+	}
+	return NameList{Names: names}, nil
+}
+
+func commandLocations(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	regionName := c.Pokedex.CurrentLocation.Region
+	if args.Has(ARG_REGION) {
+		regionName = args.String(ARG_REGION)
+	}
+	region, err := getRegion(config.Next+regionName, c)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(region.Locations))
+	for i, location := range region.Locations {
+		names[i] = location.Name
+	}
+	return NameList{Names: names}, nil
+}
+
+func commandAreas(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	locationName := c.Pokedex.CurrentLocation.Location
+	if args.Has(ARG_LOCATION) {
+		locationName = args.String(ARG_LOCATION)
+	}
+	location, err := getLocation(config.Next+locationName, c)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(location.Areas))
+	for i, area := range location.Areas {
+		names[i] = area.Name
+	}
+	return NameList{Names: names, Empty: fmt.Sprintf("%s has no location areas", location.Name)}, nil
+}
+
+// getEncounterAreas returns the areas where a Pokémon can be encountered, from cache when possible
+func getEncounterAreas(endpoint string, c *cache.Cache) ([]api.LocationAreaEncounter, error) {
+	return getCached(CMD_WHERE+" "+endpoint, c, func() ([]api.LocationAreaEncounter, error) {
+		return api.GetLocationAreaEncounters(endpoint)
+	})
+}
+
+type encounterSummary struct {
+	Area     string
+	Version  string
+	Method   string
+	Chance   int
+	MinLevel int
+	MaxLevel int
+}
+
+// summarizeEncounters merges the encounter details of each area and version by method,
+// adding up their chances and widening the level range
+func summarizeEncounters(encounters []api.LocationAreaEncounter) []encounterSummary {
+	summaries := []encounterSummary{}
+	for _, encounter := range encounters {
+		for _, versionDetail := range encounter.VersionDetails {
+			byMethod := map[string]int{}
+			for _, detail := range versionDetail.EncounterDetails {
+				idx, ok := byMethod[detail.Method.Name]
+				if !ok {
+					byMethod[detail.Method.Name] = len(summaries)
+					summaries = append(summaries, encounterSummary{
+						Area:     encounter.LocationArea.Name,
+						Version:  versionDetail.Version.Name,
+						Method:   detail.Method.Name,
+						Chance:   detail.Chance,
+						MinLevel: detail.MinLevel,
+						MaxLevel: detail.MaxLevel,
+					})
+					continue
+				}
+				summary := &summaries[idx]
+				summary.Chance += detail.Chance
+				summary.MinLevel = min(summary.MinLevel, detail.MinLevel)
+				summary.MaxLevel = max(summary.MaxLevel, detail.MaxLevel)
+			}
+		}
+	}
+	return summaries
+}
+
+// REGION_UNKNOWN groups the encounter areas whose region couldn't be looked up
+const REGION_UNKNOWN string = "unknown"
+
+// areaRegion returns the region a location area belongs to, from cache when possible
+func areaRegion(area string, c *cache.Cache) (string, error) {
+	locationArea, err := getLocationArea(api.ENDPOINT_LOCATION_AREA+area, c)
+	if err != nil {
+		return "", err
+	}
+	location, err := getLocation(api.ENDPOINT_LOCATION+locationArea.Location.Name, c)
+	if err != nil {
+		return "", err
+	}
+	return location.Region.Name, nil
+}
+
+func commandWhere(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	pokemonName := args.String(ARG_POKEMON)
+	encounters, err := getEncounterAreas(config.Next+pokemonName+"/encounters", c)
+	if err != nil {
+		return nil, err
+	}
+	result := PokemonEncounters{Pokemon: pokemonName, Areas: []EncounterArea{}}
+	// an area has a row per version and method, but is looked up once
+	regions := map[string]string{}
+	for _, summary := range summarizeEncounters(encounters) {
+		region, ok := regions[summary.Area]
+		if !ok {
+			region, err = areaRegion(summary.Area, c)
+			if err != nil {
+				region = REGION_UNKNOWN
+			}
+			regions[summary.Area] = region
+		}
+		result.Areas = append(result.Areas, EncounterArea{
+			Region:   region,
+			Area:     summary.Area,
+			Version:  summary.Version,
+			Method:   summary.Method,
+			Chance:   summary.Chance,
+			MinLevel: summary.MinLevel,
+			MaxLevel: summary.MaxLevel,
+			Current:  summary.Area == c.Pokedex.CurrentLocation.LocationArea,
+		})
+	}
+	return result, nil
+}
+
+func commandLook(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	current := c.Pokedex.CurrentLocation
+	graph, err := world.Load(current.Region)
+	if err != nil {
+		return unmapped(inv, c, err)
+	}
+	exits, err := graph.Exits(current.LocationArea)
+	if err != nil {
+		return unmapped(inv, c, err)
+	}
+	return Exits{Area: current.LocationArea, Exits: exits}, nil
+}
+
+// unmapped tells the player that the way out of their area isn't mapped yet and fails,
+// when that is why err was returned
+func unmapped(inv *Invocation, c *cache.Cache, err error) (Result, error) {
+	if !errors.Is(err, world.ErrUnmapped) {
+		return nil, err
+	}
+	return failed(inv.messages(), "The way out of %s isn't mapped yet. Use %s to travel instead.", c.Pokedex.CurrentLocation.LocationArea, CMD_VISIT)
+}
+
+// inTheWay tells the player to run from the wild Pokémon they are facing before
+// leaving the area, if any, and fails
+func inTheWay(inv *Invocation, c *cache.Cache) (Result, error) {
+	if c.Encounter == nil {
+		return nil, nil
+	}
+	return failed(inv.messages(), "The wild %s is in your way! Run first.", c.Encounter.Pokemon.Name)
+}
+
+func commandGo(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	if out, err := inTheWay(inv, c); err != nil {
+		return out, err
+	}
+	current := c.Pokedex.CurrentLocation
+	graph, err := world.Load(current.Region)
+	if err != nil {
+		return unmapped(inv, c, err)
+	}
+	area, err := graph.Move(current.LocationArea, args.String(ARG_DIRECTION))
+	if err != nil {
+		return unmapped(inv, c, err)
+	}
+	c.Pokedex.CurrentLocation.LocationArea = area.Name
+	c.Pokedex.CurrentLocation.Location = area.Location
+	out := inv.messages()
+	fmt.Fprintf(out, "You walked to %s.\n", area.Name)
+	return out, nil
+}
+
+// routeCosts returns the costs of exits given by the route flags of a command, each of
+// which may be repeated or list several comma separated values
+func routeCosts(command Command, values Values) (world.Costs, error) {
+	costs := world.DefaultCosts()
+	for _, avoid := range values.Strings(FLAG_AVOID) {
+		for _, tag := range strings.Split(avoid, ",") {
+			costs[tag] = world.AVOID
+		}
+	}
+	for _, cost := range values.Strings(FLAG_COST) {
+		for _, value := range strings.Split(cost, ",") {
+			tag, cost, ok := strings.Cut(value, "=")
+			n, err := strconv.Atoi(cost)
+			if !ok || err != nil || n < 1 {
+				return nil, command.usageError("invalid %s value %q, expected <tag>=<n>", FLAG_COST, value)
+			}
+			costs[tag] = n
+		}
+	}
+	return costs, nil
+}
+
+func newRoute(to string, route world.Route) Route {
+	result := Route{To: to, Steps: []world.Exit{}, Cost: route.Cost}
+	for _, step := range route.Steps {
+		result.Steps = append(result.Steps, step.Exit)
+	}
+	return result
+}
+
+func commandRoute(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	costs, err := routeCosts(inv.command, args)
+	if err != nil {
+		return nil, err
+	}
+	to := args.String(ARG_AREA)
+	current := c.Pokedex.CurrentLocation
+	graph, err := world.Load(current.Region)
+	if err != nil {
+		return unmapped(inv, c, err)
+	}
+	route, err := graph.ShortestPath(current.LocationArea, to, costs)
+	if err != nil {
+		return unmapped(inv, c, err)
+	}
+	return newRoute(to, route), nil
+}
+
+func commandHunt(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	costs, err := routeCosts(inv.command, args)
+	if err != nil {
+		return nil, err
+	}
+	pokemonName := args.String(ARG_POKEMON)
+	current := c.Pokedex.CurrentLocation
+	version := c.Pokedex.Version
+	if version == "" {
+		version = pokedex.STARTING_VERSION
+	}
+	graph, err := world.Load(current.Region)
+	if err != nil {
+		return unmapped(inv, c, err)
+	}
+	routes, err := graph.ShortestPaths(current.LocationArea, costs)
+	if err != nil {
+		return unmapped(inv, c, err)
+	}
+	encounters, err := getEncounterAreas(config.Next+pokemonName+"/encounters", c)
+	if err != nil {
+		return nil, err
+	}
+	var nearest string
+	for _, summary := range summarizeEncounters(encounters) {
+		if summary.Version != version {
+			continue
+		}
+		route, ok := routes[summary.Area]
+		if !ok {
+			continue
+		}
+		if nearest == "" || route.Cost < routes[nearest].Cost {
+			nearest = summary.Area
+		}
+	}
+	if nearest == "" {
+		out := inv.messages()
+		return failed(out, "%s can't be reached in %s (pokemon %s)", pokemonName, current.Region, version)
+	}
+	route := newRoute(nearest, routes[nearest])
+	route.Pokemon = pokemonName
+	return route, nil
+}
+
+func commandVersion(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	if args.Has(ARG_VERSION) {
+		c.Pokedex.Version = args.String(ARG_VERSION)
+	}
+	if c.Pokedex.Version == "" {
+		c.Pokedex.Version = pokedex.STARTING_VERSION
+	}
+	return GameVersion{Version: c.Pokedex.Version}, nil
+}
+
+func commandBag(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	c.Pokedex.Mu.RLock()
+	defer c.Pokedex.Mu.RUnlock()
+	bag := Bag{Items: []BagItem{}}
+	for _, ball := range battle.Balls {
+		bag.Items = append(bag.Items, BagItem{Item: ball, Count: c.Pokedex.Bag[ball]})
+	}
+	items := []string{}
+	for item, count := range c.Pokedex.Bag {
+		if count > 0 && !slices.Contains(battle.Balls, item) {
+			items = append(items, item)
+		}
+	}
+	sort.Strings(items)
+	for _, item := range items {
+		bag.Items = append(bag.Items, BagItem{Item: item, Count: c.Pokedex.Bag[item]})
+	}
+	return bag, nil
+}
+
+func commandUse(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	item := args.String(ARG_ITEM)
+	individual, ok := c.Pokedex.Find(args.String(ARG_POKEMON))
+	if !ok {
+		return failed(out, "You have not caught that pokemon")
+	}
+	c.Pokedex.Mu.RLock()
+	count := c.Pokedex.Bag[item]
+	c.Pokedex.Mu.RUnlock()
+	if count <= 0 {
+		return failed(out, "You have no %s in your bag!", item)
+	}
+	evolving, evolved, err := evolve(individual, pokedex.EvolutionContext{Trigger: pokedex.EVOLUTION_USE_ITEM, Item: item, Time: time.Now()}, out, c)
+	if err != nil {
+		return out, err
+	}
+	if !evolving {
+		return failed(out, "It won't have any effect.")
+	}
+	if !evolved {
+		return out, nil
+	}
+	return out, c.Pokedex.UseItem(item)
+}
+
+func commandTrade(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	individual, ok := c.Pokedex.Find(args.String(ARG_POKEMON))
+	if !ok {
+		return failed(out, "You have not caught that pokemon")
+	}
+	fmt.Fprintf(out, "You traded %s away... and got it back!\n", individual.Name())
+	_, _, err := evolve(individual, pokedex.EvolutionContext{Trigger: pokedex.EVOLUTION_TRADE, Time: time.Now()}, out, c)
+	return out, err
+}
+
+// getMove returns the battle data of a move, from cache when possible
+func getMove(endpoint string, c *cache.Cache) (battle.Move, error) {
+	move, err := getCached(CMD_FIGHT+" "+endpoint, c, func() (api.Move, error) {
+		return api.GetMove(endpoint)
+	})
+	if err != nil {
+		return battle.Move{}, err
+	}
+	return battle.Move{
+		Name:      move.Name,
+		Type:      move.Type.Name,
+		Class:     move.DamageClass.Name,
+		Power:     move.Power,
+		Accuracy:  move.Accuracy,
+		Priority:  move.Priority,
+		CritStage: move.Meta.CritRate,
+		Ailment:   move.Meta.Ailment.Name,
+		Chance:    move.Meta.AilmentChance,
+	}, nil
+}
+
+// statusLabel returns how a status condition is shown to the player
+func statusLabel(status pokedex.Status) string {
+	if status.Condition == "" {
+		return "healthy"
+	}
+	return status.Condition
+}
+
+// findOwned returns one of the player's Pokémon by ID or species, along with its species data
+func findOwned(query string, c *cache.Cache) (*pokedex.Individual, pokedex.Pokemon, bool) {
+	individual, ok := c.Pokedex.Find(query)
+	if !ok {
+		return nil, pokedex.Pokemon{}, false
+	}
+	entry, ok := c.Pokedex.Get(individual.Species)
+	if !ok {
+		return nil, pokedex.Pokemon{}, false
+	}
+	return individual, entry.Pokemon, true
+}
+
+// getNature returns the stat changes of a nature, a neutral one when it has no name
+func getNature(name string, c *cache.Cache) (pokedex.Nature, error) {
+	if name == "" {
+		return pokedex.Nature{}, nil
+	}
+	nature, err := getCached(CMD_INSPECT+" "+api.ENDPOINT_NATURE+name, c, func() (api.Nature, error) {
+		return api.GetNature(api.ENDPOINT_NATURE + name)
+	})
+	if err != nil {
+		return pokedex.Nature{}, err
+	}
+	return pokedex.Nature{
+		Name:      nature.Name,
+		Increased: nature.IncreasedStat.Name,
+		Decreased: nature.DecreasedStat.Name,
+	}, nil
+}
+
+// getGrowthCurve returns the experience a species needs to reach each level
+func getGrowthCurve(pokemon pokedex.Pokemon, c *cache.Cache) (pokedex.GrowthCurve, error) {
+	species, err := getPokemonSpecies(speciesEndpoint(pokemon), c)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := species.GrowthRate.URL
+	growthRate, err := getCached(CMD_CATCH+" "+endpoint, c, func() (api.GrowthRate, error) {
+		return api.GetGrowthRate(endpoint)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return growthRate.Curve(), nil
+}
+
+// gainExperience gives experience to one of the player's Pokémon. Each level it grows
+// raises its HP and teaches it the moves of its level-up learnset.
+func gainExperience(individual *pokedex.Individual, xp int, out *Messages, c *cache.Cache) error {
+	entry, ok := c.Pokedex.Get(individual.Species)
+	if !ok {
+		return nil
+	}
+	curve, err := getGrowthCurve(entry.Pokemon, c)
+	if err != nil {
+		return err
+	}
+	maxHP := battle.MaxHP(entry.Pokemon, individual)
+	levels := individual.GainExperience(xp, curve)
+	fmt.Fprintf(out, "%s gained %d XP!\n", individual.Name(), xp)
+	if len(levels) == 0 {
+		return nil
+	}
+	if individual.HP > 0 {
+		individual.HP += battle.MaxHP(entry.Pokemon, individual) - maxHP
+	}
+	for _, level := range levels {
+		fmt.Fprintf(out, "%s grew to level %d!\n", individual.Name(), level)
+		for _, move := range entry.Pokemon.MovesLearntAt(level) {
+			if err := learnMove(individual, move, out); err != nil {
+				return err
+			}
+		}
+	}
+	_, _, err = evolve(individual, pokedex.EvolutionContext{Trigger: pokedex.EVOLUTION_LEVEL_UP, Time: time.Now()}, out, c)
+	return err
+}
+
+// getEvolutions returns the ways a species evolves
+func getEvolutions(pokemon pokedex.Pokemon, c *cache.Cache) ([]pokedex.Evolution, error) {
+	species, err := getPokemonSpecies(speciesEndpoint(pokemon), c)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := species.EvolutionChain.URL
+	if endpoint == "" {
+		return nil, nil
+	}
+	chain, err := getCached(CMD_CATCH+" "+endpoint, c, func() (api.EvolutionChain, error) {
+		return api.GetEvolutionChain(endpoint)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chain.Evolutions(pokemon.Name), nil
+}
+
+// evolve evolves one of the player's Pokémon when it meets the conditions of one of
+// its species' evolutions, unless the player cancels it. It reports whether the
+// Pokémon met those conditions, and whether it evolved.
+func evolve(individual *pokedex.Individual, ctx pokedex.EvolutionContext, out *Messages, c *cache.Cache) (evolving, evolved bool, err error) {
+	entry, ok := c.Pokedex.Get(individual.Species)
+	if !ok {
+		return false, false, nil
+	}
+	if err := fillGender(individual, entry.Pokemon, c); err != nil {
+		return false, false, err
+	}
+	evolutions, err := getEvolutions(entry.Pokemon, c)
+	if err != nil {
+		return false, false, err
+	}
+	evolution, ok := pokedex.NextEvolution(evolutions, individual, ctx)
+	if !ok {
+		return false, false, nil
+	}
+	answer, err := ask(out, fmt.Sprintf("What? %s is evolving! Let it evolve? (y/n) ", individual.Name()))
+	if err != nil {
+		return true, false, err
+	}
+	if answer := strings.ToLower(answer); answer != "y" && answer != "yes" {
+		fmt.Fprintf(out, "Huh? %s stopped evolving!\n", individual.Name())
+		return true, false, nil
+	}
+	next, err := getPokemon(evolution.Species, c)
+	if err != nil {
+		return true, false, err
+	}
+	name := individual.Name()
+	maxHP := battle.MaxHP(entry.Pokemon, individual)
+	individual.Species = next.Name
+	c.Pokedex.Add(next)
+	if individual.HP > 0 {
+		individual.HP += battle.MaxHP(next, individual) - maxHP
+	}
+	fmt.Fprintf(out, "Congratulations! Your %s evolved into %s!\n", name, next.Name)
+	for _, move := range next.MovesLearntAt(individual.Level) {
+		if err := learnMove(individual, move, out); err != nil {
+			return true, true, err
+		}
+	}
+	return true, true, nil
+}
+
+// learnMove teaches a move to a Pokémon, asking which move to forget when it already
+// knows MAX_MOVES
+func learnMove(individual *pokedex.Individual, move string, out *Messages) error {
+	if slices.Contains(individual.Moves, move) {
+		return nil
+	}
+	if len(individual.Moves) < pokedex.MAX_MOVES {
+		individual.LearnMove(move, "")
+		fmt.Fprintf(out, "%s learned %s!\n", individual.Name(), move)
+		return nil
+	}
+	answer, err := ask(out, fmt.Sprintf("%s wants to learn %s, but already knows %s. Forget which move? (enter to give up) ",
+		individual.Name(), move, strings.Join(individual.Moves, ", ")))
+	if err != nil {
+		return err
+	}
+	forget := strings.ToLower(answer)
+	if !individual.LearnMove(move, forget) {
+		fmt.Fprintf(out, "%s did not learn %s.\n", individual.Name(), move)
+		return nil
+	}
+	fmt.Fprintf(out, "1, 2 and... Poof! %s forgot %s and learned %s!\n", individual.Name(), forget, move)
+	return nil
+}
+
+// findLead returns the Pokémon at the front of the party, along with its species data
+func findLead(c *cache.Cache) (*pokedex.Individual, pokedex.Pokemon, bool) {
+	lead, ok := c.Pokedex.Lead()
+	if !ok {
+		return nil, pokedex.Pokemon{}, false
+	}
+	return findOwned(strconv.Itoa(lead.ID), c)
+}
+
+// fighter is a Pokémon taking turns in a battle
+type fighter struct {
+	battle.Combatant
+	HP     *int
+	MaxHP  int
+	Status *pokedex.Status
+}
+
+// attack plays one move of a battle turn
+func attack(attacker fighter, defender fighter, move battle.Move, out *Messages) {
+	if ok, message := battle.CanMove(attacker.Name, attacker.Status, rand.Intn); !ok {
+		fmt.Fprintln(out, message)
+		return
+	} else if message != "" {
+		fmt.Fprintln(out, message)
+	}
+	attacker.Combatant.Status = attacker.Status.Condition
+	fmt.Fprintf(out, "%s used %s!\n", attacker.Name, move.Name)
+	hit := battle.Damage(attacker.Combatant, defender.Combatant, move, rand.Intn)
+	switch {
+	case hit.Missed:
+		fmt.Fprintf(out, "%s's attack missed!\n", attacker.Name)
+		return
+	case hit.Effectiveness == 0 && move.Class != battle.CLASS_STATUS:
+		fmt.Fprintf(out, "It doesn't affect %s...\n", defender.Name)
+		return
+	}
+	if hit.Damage > 0 {
+		if hit.Critical {
+			fmt.Fprintln(out, "A critical hit!")
+		}
+		if hit.Effectiveness > 1 {
+			fmt.Fprintln(out, "It's super effective!")
+		} else if hit.Effectiveness < 1 {
+			fmt.Fprintln(out, "It's not very effective...")
+		}
+		*defender.HP = max(*defender.HP-hit.Damage, 0)
+		fmt.Fprintf(out, "%s lost %d HP (%d/%d)\n", defender.Name, hit.Damage, *defender.HP, defender.MaxHP)
+	}
+	if *defender.HP > 0 && battle.ApplyAilment(defender.Status, defender.Types, move.Ailment, move.Chance, rand.Intn) {
+		fmt.Fprintln(out, battle.Inflicted(defender.Name, defender.Status.Condition))
+	}
+}
+
+// endTurn applies the status damage a Pokémon takes at the end of a turn
+func endTurn(f fighter, out *Messages) {
+	if *f.HP <= 0 {
+		return
+	}
+	if damage := battle.EndOfTurnDamage(*f.Status, f.MaxHP); damage > 0 {
+		*f.HP = max(*f.HP-damage, 0)
+		fmt.Fprintf(out, "%s is hurt by its %s! (%d/%d)\n", f.Name, f.Status.Condition, *f.HP, f.MaxHP)
+	}
+}
+
+func commandFight(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	encounter := c.Encounter
+	if encounter == nil {
+		return failed(out, "There is nothing to fight!")
+	}
+	lead, pokemon, ok := findLead(c)
+	if !ok {
+		return failed(out, "You have no Pokémon to fight with! Catch one first.")
+	}
+	if lead.HP <= 0 {
+		return failed(out, "%s has fainted and can't fight! Heal your Pokémon first.", lead.Name())
+	}
+	moveName := args.String(ARG_MOVE)
+	if !slices.Contains(lead.Moves, moveName) {
+		return failed(out, "%s doesn't know %s!", lead.Name(), moveName)
+	}
+	playerMove, err := getMove(config.Next+moveName, c)
+	if err != nil {
+		return nil, err
+	}
+	// the wild Pokémon picks one of its moves at random
+	wildMoveName := FALLBACK_MOVE
+	if wildMoves := encounter.Wild.Moves; len(wildMoves) > 0 {
+		wildMoveName = wildMoves[rand.Intn(len(wildMoves))]
+	}
+	wildMove, err := getMove(config.Next+wildMoveName, c)
+	if err != nil {
+		return nil, err
+	}
+
+	leadNature, err := getNature(lead.Nature, c)
+	if err != nil {
+		return nil, err
+	}
+	wildNature, err := getNature(encounter.Wild.Nature, c)
+	if err != nil {
+		return nil, err
+	}
+
+	player := fighter{
+		Combatant: battle.NewCombatant(pokemon, lead, leadNature),
+		HP:        &lead.HP,
+		MaxHP:     battle.MaxHP(pokemon, lead),
+		Status:    &lead.Status,
+	}
+	wild := fighter{
+		Combatant: battle.NewCombatant(encounter.Pokemon, encounter.Wild, wildNature),
+		HP:        &encounter.Wild.HP,
+		MaxHP:     encounter.MaxHP,
+		Status:    &encounter.Wild.Status,
+	}
+	wild.Name = "wild " + wild.Name
+	if battle.MovesFirst(player.Combatant, playerMove, wild.Combatant, wildMove, rand.Intn) {
+		attack(player, wild, playerMove, out)
+		if encounter.Wild.HP > 0 {
+			attack(wild, player, wildMove, out)
+		}
+	} else {
+		attack(wild, player, wildMove, out)
+		if lead.HP > 0 {
+			attack(player, wild, playerMove, out)
+		}
+	}
+	endTurn(player, out)
+	endTurn(wild, out)
+	if encounter.Wild.HP <= 0 {
+		fmt.Fprintf(out, "The %s fainted!\n", wild.Name)
+		c.Encounter = nil
+		if lead.HP > 0 {
+			maxHP := player.MaxHP
+			lead.GainEffort(encounter.Pokemon)
+			lead.HP += battle.MaxHP(pokemon, lead) - maxHP
+			return out, gainExperience(lead, encounter.Experience(), out, c)
+		}
+		return out, nil
+	}
+	if lead.HP <= 0 {
+		fmt.Fprintf(out, "%s fainted!\n", player.Name)
+		lead.Status = pokedex.Status{}
+	}
+	encounter.Turn++
+	return out, nil
+}
+
+func commandLead(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	if args.Has(ARG_POKEMON) {
+		individual, ok := c.Pokedex.Find(args.String(ARG_POKEMON))
+		if !ok {
+			return failed(out, "You have not caught that pokemon")
+		}
+		if err := c.Pokedex.SetLead(individual.ID); err != nil {
+			return failed(out, "Can't lead with %s: %v", individual.Name(), err)
+		}
+	}
+	lead, _, ok := findLead(c)
+	if !ok {
+		return failed(out, "You have no lead Pokémon yet... Try catch some Pokémons first!")
+	}
+	return partyMember(1, lead, c), nil
+}
+
+func commandHeal(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	for _, individual := range c.Pokedex.Individuals() {
+		entry, ok := c.Pokedex.Get(individual.Species)
+		if !ok {
+			continue
+		}
+		individual.HP = battle.MaxHP(entry.Pokemon, individual)
+		individual.Status = pokedex.Status{}
+	}
+	out := inv.messages()
+	fmt.Fprintln(out, "Your Pokémon are fighting fit!")
+	return out, nil
+}
+
+// partyMember returns the level, HP and status of one of the player's Pokémon in a
+// slot of the party or a box
+func partyMember(slot int, individual *pokedex.Individual, c *cache.Cache) PartyMember {
+	member := PartyMember{
+		Slot:     slot,
+		ID:       individual.ID,
+		Species:  individual.Species,
+		Nickname: individual.Nickname,
+		Shiny:    individual.Shiny,
+		Level:    individual.Level,
+		HP:       individual.HP,
+		Status:   statusLabel(individual.Status),
+	}
+	if entry, ok := c.Pokedex.Get(individual.Species); ok {
+		member.MaxHP = battle.MaxHP(entry.Pokemon, individual)
+	}
+	return member
+}
+
+// teamCoverage returns the types a party attacks with, STAB types and damaging moves,
+// each with the names of the party members and moves it comes from
+func teamCoverage(members []*pokedex.Individual, c *cache.Cache) (map[string][]string, error) {
+	sources := map[string][]string{}
+	for _, member := range members {
+		if entry, ok := c.Pokedex.Get(member.Species); ok {
+			for _, t := range entry.Pokemon.Types {
+				sources[t.Type.Name] = append(sources[t.Type.Name], member.Name())
+			}
+		}
+		for _, name := range member.Moves {
+			move, err := getMove(api.ENDPOINT_MOVE+name, c)
+			if err != nil {
+				return nil, err
+			}
+			if move.Power > 0 && !slices.Contains(sources[move.Type], move.Name) {
+				sources[move.Type] = append(sources[move.Type], move.Name)
+			}
+		}
+	}
+	return sources, nil
+}
+
+// pokemonTypes returns the types of the species of one of your Pokémon
+func pokemonTypes(individual *pokedex.Individual, c *cache.Cache) []string {
+	types := []string{}
+	if entry, ok := c.Pokedex.Get(individual.Species); ok {
+		for _, t := range entry.Pokemon.Types {
+			types = append(types, t.Type.Name)
+		}
+	}
+	return types
+}
+
+// suggestion is a boxed Pokémon with the gaps of the party its types close
+type suggestion struct {
+	individual *pokedex.Individual
+	hits       []string // types the party doesn't hit super effectively
+	resists    []string // types several party members are weak to
+}
+
+// suggestMembers returns the boxed Pokémon whose types best close the gaps of a party,
+// most gaps closed first
+func suggestMembers(uncovered []string, weaknesses []string, c *cache.Cache) []suggestion {
+	suggestions := []suggestion{}
+	for _, individual := range c.Pokedex.Individuals() {
+		if box, ok := c.Pokedex.Locate(individual.ID); !ok || box == pokedex.PARTY {
+			continue
+		}
+		types := pokemonTypes(individual, c)
+		s := suggestion{individual: individual}
+		for _, t := range uncovered {
+			if len(battle.SuperEffective(types, t)) > 0 {
+				s.hits = append(s.hits, t)
+			}
+		}
+		for _, t := range weaknesses {
+			if battle.Effectiveness(t, types) < 1 {
+				s.resists = append(s.resists, t)
+			}
+		}
+		if len(s.hits)+len(s.resists) > 0 {
+			suggestions = append(suggestions, s)
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return len(suggestions[i].hits)+len(suggestions[i].resists) > len(suggestions[j].hits)+len(suggestions[j].resists)
+	})
+	return suggestions[:min(len(suggestions), MAX_SUGGESTIONS)]
+}
+
+func commandTeam(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	members := c.Pokedex.PartyMembers()
+	if len(members) == 0 {
+		out := inv.messages()
+		return failed(out, "Your party is empty... Try catch some Pokémons first!")
+	}
+	sources, err := teamCoverage(members, c)
+	if err != nil {
+		return nil, err
+	}
+	attacking := []string{}
+	for t := range sources {
+		attacking = append(attacking, t)
+	}
+	sort.Strings(attacking)
+
+	analysis := TeamAnalysis{Uncovered: []string{}, SharedWeaknesses: []string{}, Suggestions: []TeamSuggestion{}}
+	for _, defending := range battle.Types {
+		coverage := TypeCoverage{Type: defending, HitBy: []CoverageSource{}}
+		for _, t := range battle.SuperEffective(attacking, defending) {
+			coverage.HitBy = append(coverage.HitBy, CoverageSource{Type: t, From: sources[t]})
+		}
+		if len(coverage.HitBy) == 0 {
+			analysis.Uncovered = append(analysis.Uncovered, defending)
+		}
+		analysis.Coverage = append(analysis.Coverage, coverage)
+	}
+
+	team := make([][]string, len(members))
+	for i, member := range members {
+		team[i] = pokemonTypes(member, c)
+	}
+	for _, matchup := range battle.Defense(team) {
+		if matchup.SharedWeakness() {
+			analysis.SharedWeaknesses = append(analysis.SharedWeaknesses, matchup.Type)
+		}
+		analysis.Defense = append(analysis.Defense, TypeMatchup{
+			Type:           matchup.Type,
+			Weak:           matchup.Weak,
+			Resist:         matchup.Resist,
+			SharedWeakness: matchup.SharedWeakness(),
+		})
+	}
+
+	if len(analysis.Uncovered)+len(analysis.SharedWeaknesses) > 0 {
+		for _, s := range suggestMembers(analysis.Uncovered, analysis.SharedWeaknesses, c) {
+			analysis.Suggestions = append(analysis.Suggestions, TeamSuggestion{
+				ID:      s.individual.ID,
+				Name:    s.individual.Name(),
+				Types:   pokemonTypes(s.individual, c),
+				Hits:    append([]string{}, s.hits...),
+				Resists: append([]string{}, s.resists...),
+			})
+		}
+	}
+	return analysis, nil
+}
+
+func commandParty(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	party := Party{Members: []PartyMember{}}
+	for i, individual := range c.Pokedex.PartyMembers() {
+		party.Members = append(party.Members, partyMember(i+1, individual, c))
+	}
+	return party, nil
+}
+
+func commandBox(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	name := args.String(FLAG_NAME)
+	if !args.Has(ARG_BOX) {
+		if name != "" {
+			return nil, inv.usageError("%s needs a box number", FLAG_NAME)
+		}
+		boxes := BoxList{Boxes: []BoxSummary{}}
+		for i, box := range c.Pokedex.Boxes {
+			boxes.Boxes = append(boxes.Boxes, BoxSummary{Box: i + 1, Name: box.Name, Count: box.Count()})
+		}
+		return boxes, nil
+	}
+	n := args.Int(ARG_BOX)
+	out := inv.messages()
+	if name != "" {
+		if err := c.Pokedex.RenameBox(n, name); err != nil {
+			return failed(out, "Can't rename box %d: %v", n, err)
+		}
+		fmt.Fprintf(out, "Box %d is now called %s.\n", n, name)
+		return out, nil
+	}
+	box, err := c.Pokedex.Box(n)
+	if err != nil {
+		return failed(out, "Can't open box %d: %v", n, err)
+	}
+	contents := BoxContents{Box: n, Name: box.Name, Pokemon: []PartyMember{}}
+	for slot, id := range box.Slots {
+		individual, ok := c.Pokedex.GetIndividual(id)
+		if !ok {
+			continue
+		}
+		contents.Pokemon = append(contents.Pokemon, partyMember(slot+1, individual, c))
+	}
+	return contents, nil
+}
+
+func commandDeposit(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	individual, ok := c.Pokedex.Find(args.String(ARG_POKEMON))
+	if !ok {
+		return failed(out, "You have not caught that pokemon")
+	}
+	box, err := c.Pokedex.Deposit(individual.ID)
+	if err != nil {
+		return failed(out, "Can't deposit %s: %v", individual.Name(), err)
+	}
+	fmt.Fprintf(out, "%s was deposited in box %d.\n", individual.Name(), box)
+	return out, nil
+}
+
+func commandWithdraw(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	individual, ok := c.Pokedex.Find(args.String(ARG_POKEMON))
+	if !ok {
+		return failed(out, "You have not caught that pokemon")
+	}
+	if err := c.Pokedex.Withdraw(individual.ID); err != nil {
+		return failed(out, "Can't withdraw %s: %v", individual.Name(), err)
+	}
+	fmt.Fprintf(out, "%s joined your party.\n", individual.Name())
+	return out, nil
+}
+
+func commandSwap(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	a, okA := c.Pokedex.Find(args.String(ARG_POKEMON))
+	b, okB := c.Pokedex.Find(args.String(ARG_OTHER))
+	if !okA || !okB {
+		return failed(out, "You have not caught that pokemon")
+	}
+	if err := c.Pokedex.Swap(a.ID, b.ID); err != nil {
+		return failed(out, "Can't swap %s and %s: %v", a.Name(), b.Name(), err)
+	}
+	fmt.Fprintf(out, "%s and %s swapped places.\n", a.Name(), b.Name())
+	return out, nil
+}
+
+func commandRelease(inv *Invocation, config *Config, args Values, c *cache.Cache) (Result, error) {
+	out := inv.messages()
+	individual, ok := c.Pokedex.Find(args.String(ARG_POKEMON))
+	if !ok {
+		return failed(out, "You have not caught that pokemon")
+	}
+	if err := c.Pokedex.Release(individual.ID); err != nil {
+		return failed(out, "Can't release %s: %v", individual.Name(), err)
+	}
+	fmt.Fprintf(out, "%s was released. Bye, %s!\n", individual.Name(), individual.Name())
+	return out, nil
 }
@@ -1,30 +1,1316 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/charlesaraya/pokedex-go/internal/api"
+	"github.com/charlesaraya/pokedex-go/internal/battle"
 	"github.com/charlesaraya/pokedex-go/internal/cache"
 	"github.com/charlesaraya/pokedex-go/internal/pokedex"
+	"github.com/charlesaraya/pokedex-go/internal/query"
+	"github.com/charlesaraya/pokedex-go/internal/render"
+	"github.com/charlesaraya/pokedex-go/internal/world"
 )
 
+// messages returns the lines of a command's result, failing when it is not Messages
+func messages(t *testing.T, result Result) []string {
+	t.Helper()
+	out, ok := result.(*Messages)
+	if !ok {
+		t.Fatalf("got result %T want messages", result)
+	}
+	return out.Lines()
+}
+
 func TestCommands(t *testing.T) {
-	registry := GetRegistry()
+	registry := NewRegistry().commands
 	duration, _ := time.ParseDuration("1s")
 	Cache := cache.NewCache(duration)
 	Cache.Pokedex = pokedex.NewPokedex()
 
 	t.Run("run whereami command", func(t *testing.T) {
 		command := registry[CMD_WHEREAMI]
-		if err := command.Command(command.Config, Cache); err != nil {
+		result, err := command.Call(nil, Cache)
+		if err != nil {
 			t.Errorf("error %q command", CMD_WHEREAMI)
 		}
+		want := PlayerLocation{Region: pokedex.STARTING_REGION, Location: pokedex.STARTING_LOCATION, Area: pokedex.STARTING_LOCATION_AREA}
+		if result != want {
+			t.Errorf("got %+v want %+v", result, want)
+		}
+		for _, format := range render.Formats {
+			if err := command.registry.Run(command, []string{FLAG_WHEREAMI_R, FLAG_OUTPUT, format}, Cache); err != nil {
+				t.Errorf("error %q command with output %s", CMD_WHEREAMI, format)
+			}
+		}
+		if err := command.registry.Run(command, []string{FLAG_OUTPUT, "xml"}, Cache); err == nil {
+			t.Errorf("an unknown output format should fail")
+		}
+	})
+
+	t.Run("run look command", func(t *testing.T) {
+		command := registry[CMD_LOOK]
+		result, err := command.Call(nil, Cache)
+		if err != nil {
+			t.Errorf("error %q command", CMD_LOOK)
+		}
+		exits, ok := result.(Exits)
+		if !ok || exits.Area != pokedex.STARTING_LOCATION_AREA || len(exits.Exits) == 0 {
+			t.Errorf("got %+v want the exits of %s", result, pokedex.STARTING_LOCATION_AREA)
+		}
+	})
+
+	t.Run("run go command", func(t *testing.T) {
+		command := registry[CMD_GO]
+		result, err := command.Call([]string{"north"}, Cache)
+		if err != nil {
+			t.Errorf("error %q command", CMD_GO)
+		}
+		if got, want := Cache.Pokedex.CurrentLocation.LocationArea, "kanto-route-1-area"; got != want {
+			t.Errorf("got %s want %s", got, want)
+		}
+		if got := messages(t, result); !slices.Equal(got, []string{"You walked to kanto-route-1-area."}) {
+			t.Errorf("got messages %q", got)
+		}
+		if _, err := command.Call([]string{"1"}, Cache); err != nil {
+			t.Errorf("error %q command", CMD_GO)
+		}
+		if got, want := Cache.Pokedex.CurrentLocation.LocationArea, "pallet-town-area"; got != want {
+			t.Errorf("got %s want %s", got, want)
+		}
+	})
+
+	t.Run("run go command during an encounter", func(t *testing.T) {
+		Cache.Encounter = battle.NewEncounter(pokedex.Pokemon{Name: "rattata"}, &pokedex.Individual{Species: "rattata", Level: 2}, 255)
+		defer func() { Cache.Encounter = nil }()
+		before := Cache.Pokedex.CurrentLocation
+		for _, cmd := range []string{CMD_GO, CMD_VISIT} {
+			command := registry[cmd]
+			result, err := command.Call([]string{"north"}, Cache)
+			if !errors.Is(err, ErrFailed) {
+				t.Errorf("%q command should fail, got %v", cmd, err)
+			}
+			if got := messages(t, result); !slices.Equal(got, []string{"The wild rattata is in your way! Run first."}) {
+				t.Errorf("got messages %q", got)
+			}
+			if Cache.Pokedex.CurrentLocation != before {
+				t.Errorf("%s should not leave the area during an encounter", cmd)
+			}
+		}
+	})
+
+	t.Run("run look, go and route commands outside the map", func(t *testing.T) {
+		defer func(location pokedex.PlayerLocation) { Cache.Pokedex.CurrentLocation = location }(Cache.Pokedex.CurrentLocation)
+		Cache.Pokedex.CurrentLocation = pokedex.PlayerLocation{Region: "johto", Location: "new-bark-town", LocationArea: "new-bark-town-area"}
+		for cmd, params := range map[string][]string{CMD_LOOK: nil, CMD_GO: {"north"}, CMD_ROUTE: {"violet-city-area"}} {
+			result, err := registry[cmd].Call(params, Cache)
+			if !errors.Is(err, ErrFailed) {
+				t.Errorf("%q command should fail, got %v", cmd, err)
+			}
+			if got := messages(t, result); !slices.Equal(got, []string{"The way out of new-bark-town-area isn't mapped yet. Use visit to travel instead."}) {
+				t.Errorf("got messages %q", got)
+			}
+		}
+	})
+
+	t.Run("run catch command without encounter", func(t *testing.T) {
+		command := registry[CMD_CATCH]
+		result, err := command.Call(nil, Cache)
+		if !errors.Is(err, ErrFailed) {
+			t.Errorf("%q command should fail, got %v", CMD_CATCH, err)
+		}
+		if got := messages(t, result); !slices.Equal(got, []string{"Nothing to catch!"}) {
+			t.Errorf("got messages %q", got)
+		}
+		if got, want := Cache.Pokedex.Bag[battle.BALL_POKE], pokedex.NewBag()[battle.BALL_POKE]; got != want {
+			t.Errorf("got %d poke-balls want %d, no ball should be thrown", got, want)
+		}
+	})
+
+	t.Run("run run command", func(t *testing.T) {
+		Cache.Encounter = battle.NewEncounter(pokedex.Pokemon{Name: "pidgey"}, &pokedex.Individual{Species: "pidgey", Level: 3}, 255)
+		command := registry[CMD_RUN]
+		result, err := command.Call(nil, Cache)
+		if err != nil {
+			t.Errorf("error %q command", CMD_RUN)
+		}
+		if Cache.Encounter != nil {
+			t.Errorf("encounter should have ended")
+		}
+		if got := messages(t, result); !slices.Equal(got, []string{"Got away safely!"}) {
+			t.Errorf("got messages %q", got)
+		}
+	})
+
+	t.Run("run lead and heal commands", func(t *testing.T) {
+		Cache.Pokedex.Add(pokedex.Pokemon{Name: "pikachu"})
+		id := Cache.Pokedex.AddIndividual(&pokedex.Individual{Species: "pikachu", Level: 5})
+		Cache.Pokedex.Store(id)
+		command := registry[CMD_LEAD]
+		result, err := command.Call([]string{"pikachu"}, Cache)
+		if err != nil {
+			t.Errorf("error %q command", CMD_LEAD)
+		}
+		if lead, ok := Cache.Pokedex.Lead(); !ok || lead.ID != id {
+			t.Errorf("got lead %v want #%d", lead, id)
+		}
+		if member, ok := result.(PartyMember); !ok || member.ID != id || member.Level != 5 {
+			t.Errorf("got %+v want #%d", result, id)
+		}
+		command = registry[CMD_HEAL]
+		if _, err := command.Call(nil, Cache); err != nil {
+			t.Errorf("error %q command", CMD_HEAL)
+		}
+		individual, _ := Cache.Pokedex.GetIndividual(id)
+		if individual.HP != battle.HPStat(0, 0, 0, 5) {
+			t.Errorf("got %d HP want %d", individual.HP, battle.HPStat(0, 0, 0, 5))
+		}
+	})
+
+	t.Run("run party and box commands", func(t *testing.T) {
+		id := Cache.Pokedex.AddIndividual(&pokedex.Individual{Species: "pikachu", Level: 7})
+		Cache.Pokedex.Store(id)
+		steps := []struct {
+			cmd    string
+			params []string
+			box    int
+		}{
+			{cmd: CMD_DEPOSIT, params: []string{"#2"}, box: 1},
+			{cmd: CMD_BOX, params: []string{"1", FLAG_NAME, "electric", "mice"}, box: 1},
+			{cmd: CMD_BOX, params: []string{"1"}, box: 1},
+			{cmd: CMD_SWAP, params: []string{"#1", "#2"}, box: pokedex.PARTY},
+			{cmd: CMD_WITHDRAW, params: []string{"#1"}, box: pokedex.PARTY},
+			{cmd: CMD_PARTY, box: pokedex.PARTY},
+		}
+		results := []Result{}
+		for _, step := range steps {
+			command := registry[step.cmd]
+			result, err := command.Call(step.params, Cache)
+			if err != nil {
+				t.Errorf("error %q command", step.cmd)
+			}
+			if box, _ := Cache.Pokedex.Locate(id); box != step.box {
+				t.Errorf("%s: got #%d in box %d want box %d", step.cmd, id, box, step.box)
+			}
+			results = append(results, result)
+		}
+		if _, err := registry[CMD_BOX].Call([]string{FLAG_NAME, "grass"}, Cache); !errors.Is(err, ErrUsage) {
+			t.Errorf("naming a box without its number should be a usage error, got %v", err)
+		}
+		if box, _ := Cache.Pokedex.Box(1); box.Name != "electric mice" {
+			t.Errorf("got box name %q want %q", box.Name, "electric mice")
+		}
+		if contents, ok := results[2].(BoxContents); !ok || contents.Name != "electric mice" || len(contents.Pokemon) != 1 || contents.Pokemon[0].ID != id {
+			t.Errorf("got box %+v want #%d in electric mice", results[2], id)
+		}
+		if party, ok := results[5].(Party); !ok || len(party.Members) != 2 || party.Members[1].Slot != 2 {
+			t.Errorf("got party %+v want two members", results[5])
+		}
+		command := registry[CMD_RELEASE]
+		if _, err := command.Call([]string{"#2"}, Cache); err != nil {
+			t.Errorf("error %q command", CMD_RELEASE)
+		}
+		if _, ok := Cache.Pokedex.GetIndividual(id); ok {
+			t.Errorf("#%d should have been released", id)
+		}
+	})
+
+	t.Run("run rename command", func(t *testing.T) {
+		command := registry[CMD_RENAME]
+		if _, err := command.Call([]string{"#1", "Sparky"}, Cache); err != nil {
+			t.Errorf("error %q command", CMD_RENAME)
+		}
+		individual, ok := Cache.Pokedex.Find("sparky")
+		if !ok || individual.ID != 1 || individual.Nickname != "Sparky" {
+			t.Errorf("#1 should be nicknamed Sparky, got %+v", individual)
+		}
+		got := command.registry.Completions([]string{CMD_INSPECT, "sp"}, Cache)
+		if !slices.Contains(got, "Sparky") || !slices.Contains(got, "#1") || !slices.Contains(got, "pikachu") {
+			t.Errorf("got completions %q", got)
+		}
+		result, err := command.Call([]string{"Sparky", "Sparky!"}, Cache)
+		if !errors.Is(err, ErrFailed) {
+			t.Errorf("%q command should fail, got %v", CMD_RENAME, err)
+		}
+		if individual.Nickname != "Sparky" {
+			t.Errorf("an invalid nickname shouldn't be given, got %q", individual.Nickname)
+		}
+		if lines := messages(t, result); len(lines) != 1 || !strings.HasPrefix(lines[0], "Invalid nickname") {
+			t.Errorf("got messages %q", lines)
+		}
+	})
+
+	t.Run("run shiny command", func(t *testing.T) {
+		command := registry[CMD_SHINY]
+		result, err := command.Call([]string{FLAG_ODDS, "1"}, Cache)
+		if err != nil {
+			t.Errorf("error %q command", CMD_SHINY)
+		}
+		if status, ok := result.(ShinyStatus); !ok || status.Odds != 1 || status.ShinyCharm {
+			t.Errorf("got %+v want odds of 1 without the Shiny Charm", result)
+		}
+		if !Cache.Pokedex.RollShiny(func(n int) int { return n - 1 }) {
+			t.Errorf("every wild Pokémon should be shiny with odds of 1")
+		}
+		if _, err := command.Call([]string{FLAG_ODDS, "0"}, Cache); !errors.Is(err, ErrUsage) {
+			t.Errorf("odds of 0 should be rejected with a usage error, got %v", err)
+		}
+		command = registry[CMD_POKEDEX]
+		result, err = command.Call([]string{FLAG_SHINY}, Cache)
+		if err != nil {
+			t.Errorf("error %q command", CMD_POKEDEX)
+		}
+		if list, ok := result.(PokemonList); !ok || len(list.Pokemon) != 0 {
+			t.Errorf("got %+v want no shiny Pokémon", result)
+		}
+	})
+
+	t.Run("run fight command without encounter", func(t *testing.T) {
+		command := registry[CMD_FIGHT]
+		result, err := command.Call([]string{"thunder-shock"}, Cache)
+		if !errors.Is(err, ErrFailed) {
+			t.Errorf("%q command should fail, got %v", CMD_FIGHT, err)
+		}
+		if got := messages(t, result); !slices.Equal(got, []string{"There is nothing to fight!"}) {
+			t.Errorf("got messages %q", got)
+		}
+	})
+
+	t.Run("run exit command", func(t *testing.T) {
+		command := registry[CMD_EXIT]
+		result, err := command.Call(nil, Cache)
+		if !errors.Is(err, ErrExit) {
+			t.Errorf("got %v want %v", err, ErrExit)
+		}
+		if got := messages(t, result); !slices.Equal(got, []string{"Closing the Pokedex... Goodbye!"}) {
+			t.Errorf("got messages %q", got)
+		}
 	})
 
 	t.Run("run explore command", func(t *testing.T) {
+		area := Cache.Pokedex.CurrentLocation.LocationArea
+		seed(t, Cache, CMD_EXPLORE+area, []pokedex.Pokemon{{Name: "caterpie"}, {Name: "weedle"}})
 		command := registry[CMD_EXPLORE]
-		if err := command.Command(command.Config, Cache); err != nil {
-			t.Errorf("error %q command", CMD_EXPLORE)
+		result, err := command.Call(nil, Cache)
+		if err != nil {
+			t.Errorf("error %q command: %v", CMD_EXPLORE, err)
+		}
+		if got, ok := result.(AreaPokemon); !ok || got.Area != area || !slices.Equal(got.Pokemon, []string{"caterpie", "weedle"}) {
+			t.Errorf("got %+v want caterpie and weedle in %s", result, area)
+		}
+		if entry, ok := Cache.Pokedex.Get("weedle"); !ok || entry.SeenIn != area {
+			t.Errorf("weedle should be seen in %s", area)
+		}
+	})
+}
+
+func TestMessages(t *testing.T) {
+	var shown bytes.Buffer
+	out := &Messages{}
+	fmt.Fprint(out, "Throwing a poke-ball at pikachu! ")
+	if err := out.Flush(); err != nil || shown.Len() != 0 {
+		t.Errorf("messages shouldn't be shown when not rendering text, got %q", shown.String())
+	}
+	out.live = &shown
+	fmt.Fprint(out, "1... ")
+	out.Flush()
+	fmt.Fprintln(out, "caught!")
+	fmt.Fprintln(out, "pikachu was caught! (#1)")
+	if got, want := shown.String(), "Throwing a poke-ball at pikachu! 1... "; got != want {
+		t.Errorf("got %q shown want %q", got, want)
+	}
+	var rest bytes.Buffer
+	out.Text(&rest)
+	if got, want := rest.String(), "caught!\npikachu was caught! (#1)\n"; got != want {
+		t.Errorf("got %q rendered want %q", got, want)
+	}
+	want := []string{"Throwing a poke-ball at pikachu! 1... caught!", "pikachu was caught! (#1)"}
+	if !slices.Equal(out.Lines(), want) {
+		t.Errorf("got lines %q want %q", out.Lines(), want)
+	}
+	data, err := json.Marshal(out)
+	if err != nil || !strings.Contains(string(data), `"messages":["Throwing`) {
+		t.Errorf("got %s, %v", data, err)
+	}
+}
+
+func TestSummarizeEncounters(t *testing.T) {
+	encounters := []api.LocationAreaEncounter{
+		{
+			LocationArea: api.NamedResource{Name: "viridian-forest-area"},
+			VersionDetails: []api.VersionEncounterDetail{
+				{
+					Version: api.NamedResource{Name: "red"},
+					EncounterDetails: []api.EncounterDetail{
+						{Chance: 5, MinLevel: 3, MaxLevel: 3, Method: api.NamedResource{Name: "walk"}},
+						{Chance: 10, MinLevel: 5, MaxLevel: 5, Method: api.NamedResource{Name: "walk"}},
+						{Chance: 20, MinLevel: 10, MaxLevel: 15, Method: api.NamedResource{Name: "surf"}},
+					},
+				},
+			},
+		},
+	}
+	want := []encounterSummary{
+		{Area: "viridian-forest-area", Version: "red", Method: "walk", Chance: 15, MinLevel: 3, MaxLevel: 5},
+		{Area: "viridian-forest-area", Version: "red", Method: "surf", Chance: 20, MinLevel: 10, MaxLevel: 15},
+	}
+	got := summarizeEncounters(encounters)
+	if len(got) != len(want) {
+		t.Fatalf("got %d summaries want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %+v want %+v", got[i], want[i])
+		}
+	}
+}
+
+func TestWhere(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	walk := []api.EncounterDetail{{Chance: 5, MinLevel: 3, MaxLevel: 5, Method: api.NamedResource{Name: "walk"}}}
+	seed(t, Cache, CMD_WHERE+" "+api.ENDPOINT_POKEMON+"pikachu/encounters", []api.LocationAreaEncounter{
+		{
+			LocationArea: api.NamedResource{Name: "viridian-forest-area"},
+			VersionDetails: []api.VersionEncounterDetail{
+				{Version: api.NamedResource{Name: "red"}, EncounterDetails: walk},
+				{Version: api.NamedResource{Name: "blue"}, EncounterDetails: walk},
+			},
+		},
+		{
+			LocationArea:   api.NamedResource{Name: "lost-area"},
+			VersionDetails: []api.VersionEncounterDetail{{Version: api.NamedResource{Name: "red"}, EncounterDetails: walk}},
+		},
+	})
+	seed(t, Cache, CMD_VISIT+" "+api.ENDPOINT_LOCATION_AREA+"viridian-forest-area", api.LocationArea{
+		Name:     "viridian-forest-area",
+		Location: api.NamedResource{Name: "viridian-forest"},
+	})
+	seed(t, Cache, CMD_AREAS+" "+api.ENDPOINT_LOCATION+"viridian-forest", api.Location{
+		Name:   "viridian-forest",
+		Region: api.NamedResource{Name: "kanto"},
+	})
+
+	command, _ := NewRegistry().Command(CMD_WHERE)
+	result, err := command.Call([]string{"pikachu"}, Cache)
+	if err != nil {
+		t.Fatalf("error %q command: %v", CMD_WHERE, err)
+	}
+	encounters, ok := result.(PokemonEncounters)
+	if !ok {
+		t.Fatalf("got %T want PokemonEncounters", result)
+	}
+	want := []string{"kanto viridian-forest-area red", "kanto viridian-forest-area blue", REGION_UNKNOWN + " lost-area red"}
+	got := []string{}
+	for _, area := range encounters.Areas {
+		got = append(got, area.Region+" "+area.Area+" "+area.Version)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q want %q, an area that can't be looked up should not fail the others", got, want)
+	}
+}
+
+func TestParseRouteFlags(t *testing.T) {
+	registry := NewRegistry().commands
+	values, err := registry[CMD_ROUTE].parseArgs([]string{"cinnabar-island-area", "--avoid", "surf,gated", "--cost", "cut=3", "--avoid", "bike"}, nil)
+	if err != nil {
+		t.Fatalf("error parsing route flags: %v", err)
+	}
+	if got := values.String(ARG_AREA); got != "cinnabar-island-area" {
+		t.Errorf("got area %q", got)
+	}
+	costs, err := routeCosts(registry[CMD_ROUTE], values)
+	if err != nil {
+		t.Fatalf("error reading route costs: %v", err)
+	}
+	want := world.Costs{"surf": world.AVOID, "gated": world.AVOID, "bike": world.AVOID, "cut": 3}
+	for tag, cost := range want {
+		if costs[tag] != cost {
+			t.Errorf("got cost %d for %s want %d", costs[tag], tag, cost)
+		}
+	}
+	if _, err := routeCosts(registry[CMD_ROUTE], Values{FLAG_COST: {"surf"}}); !errors.Is(err, ErrUsage) {
+		t.Errorf("expected a usage error for a cost without value, got %v", err)
+	}
+	if _, err := registry[CMD_ROUTE].parseArgs([]string{"cinnabar-island-area", "--avoid"}, nil); !errors.Is(err, ErrUsage) {
+		t.Errorf("expected a usage error for a flag without value, got %v", err)
+	}
+}
+
+func TestParseBallFlag(t *testing.T) {
+	cases := []struct {
+		input           []string
+		expectedPokemon string
+		expectedBall    string
+	}{
+		{
+			input:        []string{},
+			expectedBall: battle.BALL_POKE,
+		},
+		{
+			input:           []string{"--ball", "great", "pikachu"},
+			expectedPokemon: "pikachu",
+			expectedBall:    battle.BALL_GREAT,
+		},
+		{
+			input:           []string{"pikachu", "--ball", "dusk-ball"},
+			expectedPokemon: "pikachu",
+			expectedBall:    battle.BALL_DUSK,
+		},
+	}
+	command := NewRegistry().commands[CMD_CATCH]
+	for _, c := range cases {
+		values, err := command.parseArgs(c.input, nil)
+		if err != nil {
+			t.Errorf("error parsing %v: %v", c.input, err)
+		}
+		if got := values.String(ARG_POKEMON); got != c.expectedPokemon {
+			t.Errorf("got pokemon %q want %q", got, c.expectedPokemon)
+		}
+		if ball := ballName(values.String(FLAG_BALL)); ball != c.expectedBall {
+			t.Errorf("got ball %s want %s", ball, c.expectedBall)
+		}
+	}
+	if _, err := command.parseArgs([]string{"--ball"}, nil); !errors.Is(err, ErrUsage) {
+		t.Errorf("expected a usage error for a ball flag without value, got %v", err)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	registry := NewRegistry().commands
+	cases := []struct {
+		name     string
+		command  string
+		params   []string
+		args     []string
+		expected Values
+		fails    string
+	}{
+		{
+			name:     "positional",
+			command:  CMD_SWAP,
+			params:   []string{"#1", "pikachu"},
+			expected: Values{ARG_POKEMON: {"#1"}, ARG_OTHER: {"pikachu"}},
+		},
+		{
+			name:    "missing required argument",
+			command: CMD_INSPECT,
+			params:  []string{},
+			fails:   "missing <id|pokemon>, usage: inspect <id|pokemon>",
+		},
+		{
+			name:    "unexpected argument",
+			command: CMD_INSPECT,
+			params:  []string{"#1", "#2"},
+			fails:   `unexpected argument "#2"`,
+		},
+		{
+			name:    "unknown flag",
+			command: CMD_INSPECT,
+			params:  []string{"#1", "--shiny"},
+			fails:   "unknown flag --shiny",
+		},
+		{
+			name:    "invalid number",
+			command: CMD_BOX,
+			params:  []string{"two"},
+			fails:   `invalid box "two", expected a number`,
+		},
+		{
+			name:    "not a choice",
+			command: CMD_TEAM,
+			params:  []string{"build"},
+			fails:   `invalid action "build", expected analyze`,
+		},
+		{
+			name:    "flag without value",
+			command: CMD_SHINY,
+			params:  []string{FLAG_ODDS},
+			fails:   "flag --odds needs a value",
+		},
+		{
+			name:     "variadic argument",
+			command:  CMD_POKEDEX,
+			params:   []string{"type:fire", FLAG_PROGRESS, "sort:-id"},
+			expected: Values{ARG_QUERY: {"type:fire", "sort:-id"}, FLAG_PROGRESS: {"true"}},
+		},
+		{
+			name:     "variadic flag",
+			command:  CMD_BOX,
+			params:   []string{"2", FLAG_NAME, "electric", "mice"},
+			expected: Values{ARG_BOX: {"2"}, FLAG_NAME: {"electric mice"}},
+		},
+		{
+			name:     "text as typed",
+			command:  CMD_RENAME,
+			params:   []string{"#1", "sparky"},
+			args:     []string{"#1", "Sparky"},
+			expected: Values{ARG_POKEMON: {"#1"}, ARG_NICKNAME: {"Sparky"}},
+		},
+		{
+			name:     "default",
+			command:  CMD_CATCH,
+			params:   []string{},
+			expected: Values{FLAG_BALL: {battle.BALL_POKE}},
+		},
+		{
+			name:     "bool flags",
+			command:  CMD_WHEREAMI,
+			params:   []string{FLAG_WHEREAMI_L},
+			expected: Values{FLAG_WHEREAMI_L: {"true"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			values, err := registry[c.command].parseArgs(c.params, c.args)
+			if c.fails != "" {
+				if !errors.Is(err, ErrUsage) || !strings.Contains(err.Error(), c.fails) {
+					t.Errorf("got error %v want a usage error with %q", err, c.fails)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error parsing %v: %v", c.params, err)
+			}
+			if len(values) != len(c.expected) {
+				t.Errorf("got %v want %v", values, c.expected)
+			}
+			for name, want := range c.expected {
+				if got := values.Strings(name); !slices.Equal(got, want) {
+					t.Errorf("got %s %q want %q", name, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("inspect without argument", func(t *testing.T) {
+		command := registry[CMD_INSPECT]
+		if _, err := command.Call(nil, nil); !errors.Is(err, ErrUsage) {
+			t.Errorf("got error %v want a usage error", err)
+		}
+	})
+}
+
+func TestHelp(t *testing.T) {
+	registry := NewRegistry().commands
+	command := registry[CMD_HELP]
+	result, err := command.Call([]string{CMD_BOX}, nil)
+	if err != nil {
+		t.Fatalf("error %q command: %v", CMD_HELP, err)
+	}
+	help, ok := result.(CommandHelp)
+	if !ok {
+		t.Fatalf("got %T want CommandHelp", result)
+	}
+	if want := "box [<n>] [--name <name>...]"; help.Usage != want {
+		t.Errorf("got usage %q want %q", help.Usage, want)
+	}
+	var b bytes.Buffer
+	if err := help.Text(&b); err != nil {
+		t.Fatalf("error writing help: %v", err)
+	}
+	for _, want := range []string{"usage: box", "TYPE", "int", "Renames the box.", "-o <format>", "text|json|csv|yaml", FLAG_JSON} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("help %q should contain %q", b.String(), want)
+		}
+	}
+	if _, err := command.Call([]string{"fly"}, nil); !errors.Is(err, ErrUsage) || errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("got error %v want a usage error of help", err)
+	}
+}
+
+func TestLearnMove(t *testing.T) {
+	cases := []struct {
+		answer   string
+		expected []string
+	}{
+		{answer: "Growl", expected: []string{"tackle", "thunder-shock", "tail-whip", "quick-attack"}},
+		{answer: "", expected: []string{"tackle", "growl", "tail-whip", "quick-attack"}},
+	}
+	for _, c := range cases {
+		out := &Messages{prompt: func(string) (string, error) { return c.answer, nil }}
+		individual := &pokedex.Individual{Moves: []string{"tackle", "growl", "tail-whip"}}
+		for _, move := range []string{"quick-attack", "thunder-shock"} {
+			if err := learnMove(individual, move, out); err != nil {
+				t.Fatalf("error learning %s: %v", move, err)
+			}
+		}
+		if !slices.Equal(individual.Moves, c.expected) {
+			t.Errorf("answering %q: got %v want %v", c.answer, individual.Moves, c.expected)
+		}
+	}
+}
+
+func TestEvolve(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	// seed the cache so that no request hits the PokéAPI
+	chain := api.ENDPOINT_EVOLUTION + "10"
+	seed := map[string]string{
+		CMD_CATCH + " " + api.ENDPOINT_SPECIES + "pikachu": `{"name": "pikachu", "evolution_chain": {"url": "` + chain + `"}}`,
+		CMD_CATCH + " " + chain: `{"id": 10, "chain": {"species": {"name": "pichu"}, "evolves_to": [
+			{"species": {"name": "pikachu"}, "evolution_details": [{"trigger": {"name": "level-up"}, "min_happiness": 220}], "evolves_to": [
+				{"species": {"name": "raichu"}, "evolution_details": [{"trigger": {"name": "use-item"}, "item": {"name": "thunder-stone"}}]}
+			]}
+		]}}`,
+		CMD_EXPLORE + "raichu": `{"name": "raichu", "stats": [{"stat": {"name": "hp"}, "base_stat": 60}]}`,
+	}
+	for key, val := range seed {
+		Cache.Add(key, []byte(val))
+	}
+	Cache.Pokedex.Add(pokedex.Pokemon{Name: "pikachu"})
+
+	cases := []struct {
+		name     string
+		answer   string
+		item     string
+		expected string
+		stones   int
+		fails    bool
+	}{
+		{name: "wrong item", answer: "y", item: "fire-stone", expected: "pikachu", stones: 1, fails: true},
+		{name: "cancel", answer: "n", item: "thunder-stone", expected: "pikachu", stones: 1},
+		{name: "evolve", answer: "y", item: "thunder-stone", expected: "raichu", stones: 0},
+		{name: "no stone left", answer: "y", item: "thunder-stone", expected: "pikachu", stones: 0, fails: true},
+	}
+	registry := NewRegistry()
+	command, _ := registry.Command(CMD_USE)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			registry.prompt = func(string) (string, error) { return c.answer, nil }
+			individual := &pokedex.Individual{Species: "pikachu", Level: 20}
+			id := Cache.Pokedex.AddIndividual(individual)
+			_, err := command.Call([]string{c.item, strconv.Itoa(id)}, Cache)
+			if c.fails != errors.Is(err, ErrFailed) || (!c.fails && err != nil) {
+				t.Fatalf("error %q command: %v", CMD_USE, err)
+			}
+			if individual.Species != c.expected {
+				t.Errorf("got species %s want %s", individual.Species, c.expected)
+			}
+			if got := Cache.Pokedex.Bag["thunder-stone"]; got != c.stones {
+				t.Errorf("got %d thunder stones want %d", got, c.stones)
+			}
+		})
+	}
+	if entry, ok := Cache.Pokedex.Get("raichu"); !ok || !entry.Caught() {
+		t.Errorf("raichu should be caught in the Pokédex")
+	}
+
+	t.Run("scripts don't ask", func(t *testing.T) {
+		registry.prompt = func(string) (string, error) {
+			t.Errorf("a script shouldn't ask the player")
+			return "y", nil
+		}
+		Cache.Pokedex.Bag["thunder-stone"] = 1
+		individual := &pokedex.Individual{Species: "pikachu", Level: 20}
+		id := Cache.Pokedex.AddIndividual(individual)
+		if err := registry.RunScript(strings.NewReader(fmt.Sprintf("use thunder-stone %d", id)), Cache); err != nil {
+			t.Fatalf("error running script: %v", err)
+		}
+		if individual.Species != "pikachu" {
+			t.Errorf("got species %s, evolving should be declined", individual.Species)
+		}
+		if registry.prompt == nil {
+			t.Errorf("the registry should ask again once the script is done")
+		}
+	})
+}
+
+func TestShinyCharm(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	growthRate := api.ENDPOINT_GROWTH_RATE + "medium"
+	seed(t, Cache, CMD_POKEDEX+" "+api.NATIONAL_POKEDEX, map[string]any{"name": api.NATIONAL_POKEDEX, "pokemon_entries": []any{
+		map[string]any{"entry_number": 1, "pokemon_species": api.NamedResource{Name: "bulbasaur"}},
+		map[string]any{"entry_number": 25, "pokemon_species": api.NamedResource{Name: "pikachu"}},
+	}})
+	seed(t, Cache, CMD_CATCH+" "+api.ENDPOINT_SPECIES+"pikachu", api.PokemonSpecies{Name: "pikachu", GrowthRate: api.NamedResource{Name: "medium", URL: growthRate}})
+	seed(t, Cache, CMD_CATCH+" "+growthRate, api.GrowthRate{Name: "medium"})
+	Cache.Pokedex.Add(pokedex.Pokemon{Name: "bulbasaur"})
+
+	// the last species of the national dex, seen when it appears
+	pikachu := pokedex.Pokemon{Name: "pikachu"}
+	Cache.Pokedex.MarkSeen(pikachu, pokedex.STARTING_LOCATION_AREA)
+	Cache.Encounter = battle.NewEncounter(pikachu, &pokedex.Individual{Species: "pikachu", Level: 5}, 255)
+	command, _ := NewRegistry().Command(CMD_CATCH)
+	result, err := command.Call([]string{FLAG_BALL, "master"}, Cache)
+	if err != nil {
+		t.Fatalf("error %q command: %v", CMD_CATCH, err)
+	}
+	if got := Cache.Pokedex.Bag[pokedex.SHINY_CHARM]; got != 1 {
+		t.Errorf("got %d Shiny Charms want 1 for catching them all", got)
+	}
+	if got := messages(t, result); !slices.Contains(got, "You caught them all! Professor Oak gave you the Shiny Charm.") {
+		t.Errorf("got messages %q", got)
+	}
+}
+
+func TestCatchInScript(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	growthRate := api.ENDPOINT_GROWTH_RATE + "medium"
+	seed(t, Cache, CMD_CATCH+" "+api.ENDPOINT_SPECIES+"pikachu", api.PokemonSpecies{Name: "pikachu", GrowthRate: api.NamedResource{Name: "medium", URL: growthRate}})
+	seed(t, Cache, CMD_CATCH+" "+growthRate, api.GrowthRate{Name: "medium"})
+	pikachu := pokedex.Pokemon{Name: "pikachu"}
+	Cache.Pokedex.Add(pikachu)
+	Cache.Encounter = battle.NewEncounter(pikachu, &pokedex.Individual{Species: "pikachu", Level: 5}, 255)
+
+	var b bytes.Buffer
+	registry := NewRegistry()
+	registry.stdout = &b
+	registry.prompt = func(string) (string, error) { return "", nil }
+	start := time.Now()
+	if err := registry.RunScript(strings.NewReader("catch --ball master"), Cache); err != nil {
+		t.Fatalf("error running script: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("a script shouldn't wait for the ball to shake, took %v", elapsed)
+	}
+	if !strings.Contains(b.String(), "1... 2... 3... caught!") {
+		t.Errorf("got %q", b.String())
+	}
+}
+
+func TestFillGender(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	seed(t, Cache, CMD_CATCH+" "+api.ENDPOINT_SPECIES+"magnemite", api.PokemonSpecies{Name: "magnemite", GenderRate: -1})
+	seed(t, Cache, CMD_CATCH+" "+api.ENDPOINT_SPECIES+"jynx", api.PokemonSpecies{Name: "jynx", GenderRate: 8})
+	cases := []struct {
+		species  string
+		gender   string
+		expected string
+	}{
+		{species: "magnemite", expected: pokedex.GENDER_GENDERLESS},
+		{species: "jynx", expected: pokedex.GENDER_FEMALE},
+		{species: "jynx", gender: pokedex.GENDER_MALE, expected: pokedex.GENDER_MALE},
+	}
+	for _, c := range cases {
+		individual := &pokedex.Individual{Species: c.species, Gender: c.gender}
+		if err := fillGender(individual, pokedex.Pokemon{Name: c.species}, Cache); err != nil {
+			t.Fatalf("error filling the gender of %s: %v", c.species, err)
+		}
+		if individual.Gender != c.expected {
+			t.Errorf("%s: got gender %s want %s", c.species, individual.Gender, c.expected)
+		}
+	}
+}
+
+func TestPokedexQuery(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	species := map[string]string{
+		"charmander": `{"name": "charmander", "weight": 85, "types": [{"type": {"name": "fire"}}], "stats": [{"stat": {"name": "speed"}, "base_stat": 65}]}`,
+		"ponyta":     `{"name": "ponyta", "weight": 300, "types": [{"type": {"name": "fire"}}], "stats": [{"stat": {"name": "speed"}, "base_stat": 90}]}`,
+		"pikachu":    `{"name": "pikachu", "weight": 60, "types": [{"type": {"name": "electric"}}], "stats": [{"stat": {"name": "speed"}, "base_stat": 90}]}`,
+	}
+	for _, name := range []string{"charmander", "ponyta", "pikachu", "ponyta"} {
+		var pokemon pokedex.Pokemon
+		if err := json.Unmarshal([]byte(species[name]), &pokemon); err != nil {
+			t.Fatal(err)
+		}
+		Cache.Pokedex.Add(pokemon)
+		Cache.Pokedex.AddIndividual(&pokedex.Individual{Species: name, Level: 5, Gender: pokedex.GENDER_MALE, CaughtAt: time.Date(2026, 1, len(name), 12, 0, 0, 0, time.Local)})
+	}
+
+	cases := []struct {
+		terms    []string
+		expected []int
+		fails    bool
+	}{
+		{terms: []string{"type:fire", "stat.speed>=90"}, expected: []int{2, 4}},
+		{terms: []string{"stat.speed>65", "sort:-weight,-id", "limit:2"}, expected: []int{4, 2}},
+		{terms: []string{"caught<2026-01-08"}, expected: []int{2, 3, 4}},
+		{terms: []string{"char"}, expected: []int{1}},
+		{terms: []string{"colour:red"}, fails: true},
+	}
+	for _, c := range cases {
+		q, err := query.Parse(c.terms)
+		if err != nil {
+			t.Fatalf("Error [Parse]: %v", err)
+		}
+		records, err := individualRecords(Cache)
+		if err != nil {
+			t.Fatalf("error listing records: %v", err)
+		}
+		records, err = query.Apply(records, q)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%q should fail", c.terms)
+			}
+			continue
+		}
+		ids := make([]int, len(records))
+		for i, record := range records {
+			ids[i] = record.ID
+		}
+		if !slices.Equal(ids, c.expected) {
+			t.Errorf("%q: got %v want %v", c.terms, ids, c.expected)
+		}
+	}
+
+	command := NewRegistry().commands[CMD_POKEDEX]
+	result, err := command.Call([]string{"type:fire", "sort:-id"}, Cache)
+	if err != nil {
+		t.Errorf("error %q command: %v", CMD_POKEDEX, err)
+	}
+	list, ok := result.(PokemonList)
+	if !ok || len(list.Pokemon) != 3 || list.Pokemon[0].ID != 4 || list.Pokemon[2].Species != "charmander" {
+		t.Errorf("got %+v want ponyta #4, ponyta #2 and charmander", result)
+	}
+	if _, err := command.Call([]string{"limit:0"}, Cache); err == nil {
+		t.Errorf("an invalid query should fail")
+	}
+	if err := command.registry.Run(command, []string{"type:fire", FLAG_OUTPUT, render.FORMAT_CSV}, Cache); err != nil {
+		t.Errorf("error %q command: %v", CMD_POKEDEX, err)
+	}
+}
+
+func TestPokedexMissing(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	generation := api.ENDPOINT_GENERATION + "1"
+	seed(t, Cache, CMD_POKEDEX+" "+api.ENDPOINT_GENERATION, api.NamedResources{Results: []api.NamedResource{{Name: "generation-i", URL: generation}}})
+	seed(t, Cache, CMD_POKEDEX+" "+generation, api.Generation{
+		Name:           "generation-i",
+		MainRegion:     api.NamedResource{Name: "kanto"},
+		PokemonSpecies: []api.NamedResource{{Name: "pikachu"}, {Name: "bulbasaur"}, {Name: "mew"}},
+	})
+	walk := []api.EncounterDetail{{Chance: 5, MinLevel: 3, MaxLevel: 5, Method: api.NamedResource{Name: "walk"}}}
+	seed(t, Cache, CMD_WHERE+" "+api.ENDPOINT_POKEMON+"pikachu/encounters", []api.LocationAreaEncounter{
+		{
+			LocationArea: api.NamedResource{Name: "viridian-forest-area"},
+			VersionDetails: []api.VersionEncounterDetail{
+				{Version: api.NamedResource{Name: "red"}, EncounterDetails: walk},
+				{Version: api.NamedResource{Name: "blue"}, EncounterDetails: walk},
+			},
+		},
+		{
+			LocationArea:   api.NamedResource{Name: "power-plant-area"},
+			VersionDetails: []api.VersionEncounterDetail{{Version: api.NamedResource{Name: "blue"}, EncounterDetails: walk}},
+		},
+	})
+	seed(t, Cache, CMD_WHERE+" "+api.ENDPOINT_POKEMON+"mew/encounters", []api.LocationAreaEncounter{})
+	Cache.Pokedex.Add(pokedex.Pokemon{Name: "bulbasaur"})
+	Cache.Pokedex.MarkSeen(pokedex.Pokemon{Name: "pikachu"}, pokedex.STARTING_LOCATION_AREA)
+
+	command := NewRegistry().commands[CMD_POKEDEX]
+	result, err := command.Call([]string{FLAG_PROGRESS, "kanto"}, Cache)
+	if err != nil {
+		t.Fatalf("error %q command: %v", CMD_POKEDEX, err)
+	}
+	missing, ok := result.(MissingSpecies)
+	if !ok || len(missing.Missing) != 2 {
+		t.Fatalf("got %+v want mew and pikachu missing", result)
+	}
+	mew, pikachu := missing.Missing[0], missing.Missing[1]
+	if mew.Species != "mew" || mew.Seen || len(mew.FoundIn) != 0 {
+		t.Errorf("got %+v want mew unseen and found nowhere", mew)
+	}
+	if pikachu.Species != "pikachu" || !pikachu.Seen || !slices.Equal(pikachu.FoundIn, []string{"viridian-forest-area"}) {
+		t.Errorf("got %+v want pikachu seen and found in viridian forest in red", pikachu)
+	}
+}
+
+func TestParseOutputFlag(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.SetOutput("xml"); err == nil {
+		t.Errorf("an unknown default output format should fail")
+	}
+	if err := registry.SetOutput(render.FORMAT_YAML); err != nil {
+		t.Fatalf("error setting the output format: %v", err)
+	}
+	command, _ := registry.Command(CMD_INSPECT)
+	cases := []struct {
+		input           []string
+		expectedPokemon string
+		expectedFormat  string
+		fails           bool
+	}{
+		{input: []string{"pikachu"}, expectedPokemon: "pikachu", expectedFormat: render.FORMAT_YAML},
+		{input: []string{"-o", "json", "pikachu"}, expectedPokemon: "pikachu", expectedFormat: render.FORMAT_JSON},
+		{input: []string{"pikachu", "--output", "csv"}, expectedPokemon: "pikachu", expectedFormat: render.FORMAT_CSV},
+		{input: []string{"pikachu", "-o", "JSON"}, expectedPokemon: "pikachu", expectedFormat: render.FORMAT_JSON},
+		{input: []string{FLAG_JSON, "pikachu"}, expectedPokemon: "pikachu", expectedFormat: render.FORMAT_JSON},
+		{input: []string{"pikachu", "-o"}, fails: true},
+		{input: []string{"pikachu", "-o", "xml"}, fails: true},
+	}
+	for _, c := range cases {
+		values, err := command.parse(c.input)
+		if c.fails {
+			if !errors.Is(err, ErrUsage) {
+				t.Errorf("parsing %v should fail with a usage error, got %v", c.input, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("error parsing %v: %v", c.input, err)
+		}
+		if pokemon, format := values.String(ARG_POKEMON), outputFormat(values, registry.output); pokemon != c.expectedPokemon || format != c.expectedFormat {
+			t.Errorf("got %s and %s want %s and %s", pokemon, format, c.expectedPokemon, c.expectedFormat)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	var pikachu pokedex.Pokemon
+	json.Unmarshal([]byte(`{"name": "pikachu", "height": 4, "weight": 60, "types": [{"type": {"name": "electric"}}],
+		"stats": [{"stat": {"name": "hp"}, "base_stat": 35}, {"stat": {"name": "speed"}, "base_stat": 90}],
+		"abilities": [{"ability": {"name": "static"}}, {"ability": {"name": "lightning-rod"}, "is_hidden": true}]}`), &pikachu)
+	Cache.Pokedex.Add(pikachu)
+	Cache.Pokedex.AddIndividual(&pokedex.Individual{Species: "pikachu", Nickname: "Sparky", Level: 5})
+	// seed the cache so that no request hits the PokéAPI
+	Cache.Add(CMD_EXPLORE+"gyarados", []byte(`{"name": "gyarados", "height": 65, "weight": 2350,
+		"types": [{"type": {"name": "water"}}, {"type": {"name": "flying"}}],
+		"stats": [{"stat": {"name": "hp"}, "base_stat": 95}, {"stat": {"name": "speed"}, "base_stat": 81}]}`))
+
+	a, pokemonA, err := getCompared("sparky", Cache)
+	if err != nil || !a.Owned || a.Name != "Sparky #1" {
+		t.Fatalf("got %+v, %v want Sparky #1", a, err)
+	}
+	if !slices.Equal(a.Abilities, []string{"static", "lightning-rod (hidden)"}) {
+		t.Errorf("got abilities %q", a.Abilities)
+	}
+	b, pokemonB, err := getCompared("gyarados", Cache)
+	if err != nil || b.Owned || b.Name != "gyarados" {
+		t.Fatalf("got %+v, %v want gyarados", b, err)
+	}
+	comparison := comparePokemon(a, b, pokemonA, pokemonB)
+	expected := []StatComparison{
+		{Stat: "hp", Values: [2]int{35, 95}, Delta: 60},
+		{Stat: "speed", Values: [2]int{90, 81}, Delta: -9},
+		{Stat: "total", Values: [2]int{125, 176}, Delta: 51},
+	}
+	if !slices.Equal(comparison.Stats, expected) {
+		t.Errorf("got stats %+v want %+v", comparison.Stats, expected)
+	}
+	if got := comparison.Effectiveness[0]["electric"]; got != 4 {
+		t.Errorf("got electric against gyarados x%v want x4", got)
+	}
+	if got := comparison.Effectiveness[1]["water"]; got != 1 {
+		t.Errorf("got water against pikachu x%v want x1", got)
+	}
+
+	command := NewRegistry().commands[CMD_COMPARE]
+	result, err := command.Call([]string{"gyarados", "#1"}, Cache)
+	if err != nil {
+		t.Errorf("error %q command: %v", CMD_COMPARE, err)
+	}
+	if got, ok := result.(Comparison); !ok || got.Pokemon[0].Name != "gyarados" || got.Pokemon[1].Name != "Sparky #1" {
+		t.Errorf("got %+v want gyarados against Sparky #1", result)
+	}
+	for _, params := range [][]string{{"#1", "gyarados"}, {"gyarados", "sparky", FLAG_JSON}} {
+		if err := command.registry.Run(command, params, Cache); err != nil {
+			t.Errorf("error %q command %v: %v", CMD_COMPARE, params, err)
+		}
+	}
+	if _, err := command.Call([]string{"#1"}, Cache); err == nil {
+		t.Errorf("compare should need two Pokémon")
+	}
+}
+
+func TestTeamAnalyze(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	// seed the cache so that no request hits the PokéAPI
+	Cache.Add(CMD_FIGHT+" "+api.ENDPOINT_MOVE+"thunder-shock", []byte(`{"name": "thunder-shock", "power": 40, "type": {"name": "electric"}}`))
+	Cache.Add(CMD_FIGHT+" "+api.ENDPOINT_MOVE+"growl", []byte(`{"name": "growl", "type": {"name": "normal"}}`))
+	team := []struct {
+		species string
+		types   []string
+		moves   []string
+		boxed   bool
+	}{
+		{species: "pikachu", types: []string{"electric"}, moves: []string{"thunder-shock", "growl"}},
+		{species: "ponyta", types: []string{"fire"}},
+		{species: "squirtle", types: []string{"water"}, boxed: true},
+		{species: "geodude", types: []string{"rock", "ground"}, boxed: true},
+		{species: "caterpie", types: []string{"bug"}, boxed: true},
+	}
+	for _, member := range team {
+		pokemon := pokedex.Pokemon{Name: member.species}
+		for _, name := range member.types {
+			var t pokedex.PokemonType
+			t.Type.Name = name
+			pokemon.Types = append(pokemon.Types, t)
+		}
+		Cache.Pokedex.Add(pokemon)
+		id := Cache.Pokedex.AddIndividual(&pokedex.Individual{Species: member.species, Level: 5, Moves: member.moves})
+		Cache.Pokedex.Store(id)
+		if member.boxed {
+			if _, err := Cache.Pokedex.Deposit(id); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	sources, err := teamCoverage(Cache.Pokedex.PartyMembers(), Cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(sources["electric"], []string{"pikachu", "thunder-shock"}) || len(sources["normal"]) != 0 {
+		t.Errorf("got attacking types %v", sources)
+	}
+	suggestions := suggestMembers([]string{"fire", "electric", "rock"}, []string{"ground"}, Cache)
+	got := []string{}
+	for _, s := range suggestions {
+		got = append(got, s.individual.Species)
+	}
+	if !slices.Equal(got, []string{"geodude", "squirtle", "caterpie"}) {
+		t.Fatalf("got suggestions %v want geodude, squirtle then caterpie", got)
+	}
+	if !slices.Equal(suggestions[2].resists, []string{"ground"}) {
+		t.Errorf("got caterpie resisting %v", suggestions[2].resists)
+	}
+	if !slices.Equal(suggestions[0].hits, []string{"fire", "electric", "rock"}) {
+		t.Errorf("got geodude hitting %v", suggestions[0].hits)
+	}
+
+	command := NewRegistry().commands[CMD_TEAM]
+	result, err := command.Call([]string{CMD_ANALYZE}, Cache)
+	if err != nil {
+		t.Errorf("error %q command: %v", CMD_TEAM, err)
+	}
+	analysis, ok := result.(TeamAnalysis)
+	if !ok {
+		t.Fatalf("got result %T want a team analysis", result)
+	}
+	if !slices.Contains(analysis.Uncovered, "rock") || slices.Contains(analysis.Uncovered, "water") {
+		t.Errorf("got uncovered types %v", analysis.Uncovered)
+	}
+	if len(analysis.Suggestions) == 0 || analysis.Suggestions[0].Name != "geodude" {
+		t.Errorf("got suggestions %+v want geodude first", analysis.Suggestions)
+	}
+	if _, err := command.Call(nil, Cache); err == nil {
+		t.Errorf("team without analyze should fail")
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	cases := []struct {
+		name     string
+		script   string
+		expected string
+		fails    string // part of the error the script should fail with
+	}{
+		{
+			name:     "commands on lines and separated by ;",
+			script:   "whereami\n# walk north and back\n\ngo north; go south;go north\nwhereami -o json",
+			expected: "kanto-route-1-area",
+		},
+		{
+			name:     "stops at a failing command",
+			script:   "go north\ngo nowhere\ngo south",
+			expected: "kanto-route-1-area",
+			fails:    "line 2: go command produced an error",
+		},
+		{
+			name:     "stops at a command that can't be done",
+			script:   "inspect foo; go north",
+			expected: pokedex.STARTING_LOCATION_AREA,
+			fails:    "line 1: inspect command produced an error: failed: You have not caught that pokemon",
+		},
+		{
+			name:     "stops at an unknown command",
+			script:   "go north; teleport; go south",
+			expected: "kanto-route-1-area",
+			fails:    `line 1: unknown command "teleport"`,
+		},
+		{
+			name:     "exit ends the script",
+			script:   "exit\ngo north",
+			expected: pokedex.STARTING_LOCATION_AREA,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			duration, _ := time.ParseDuration("1m")
+			Cache := cache.NewCache(duration)
+			Cache.Pokedex = pokedex.NewPokedex()
+			err := NewRegistry().RunScript(strings.NewReader(c.script), Cache)
+			switch {
+			case c.fails == "" && err != nil:
+				t.Errorf("error running script: %v", err)
+			case c.fails != "" && (err == nil || !strings.Contains(err.Error(), c.fails)):
+				t.Errorf("got error %v want %s", err, c.fails)
+			}
+			if got := Cache.Pokedex.CurrentLocation.LocationArea; got != c.expected {
+				t.Errorf("got %s want %s", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	for area, pokemon := range map[string]string{"viridian-forest-area": "pikachu", pokedex.STARTING_LOCATION_AREA: "pidgey"} {
+		seed(t, Cache, CMD_EXPLORE+area, []pokedex.Pokemon{{Name: pokemon}})
+	}
+	var b bytes.Buffer
+	t.Run("arguments don't leak into the next call", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.stdout = &b
+		cases := []struct {
+			line     string
+			expected string
+		}{
+			{line: "explore viridian-forest-area -o csv", expected: "POKEMON\npikachu\n"},
+			{line: "explore -o csv", expected: "POKEMON\npidgey\n"},
+			{line: "whereami -r", expected: "kanto\n"},
+			{line: "whereami", expected: pokedex.STARTING_LOCATION_AREA + "\n"},
+		}
+		for _, c := range cases {
+			b.Reset()
+			if err := registry.Execute(c.line, Cache); err != nil {
+				t.Fatalf("error running %q: %v", c.line, err)
+			}
+			if b.String() != c.expected {
+				t.Errorf("%q got %q want %q", c.line, b.String(), c.expected)
+			}
+		}
+		if err := registry.Execute("go north", Cache); err != nil {
+			t.Fatalf("error running go: %v", err)
+		}
+		if err := registry.Execute("go", Cache); !errors.Is(err, ErrUsage) {
+			t.Errorf("go without a direction should not go north again, got %v", err)
+		}
+	})
+
+	t.Run("state is kept per registry", func(t *testing.T) {
+		registry, other := NewRegistry(), NewRegistry()
+		mapCommand, _ := registry.Command(CMD_MAP)
+		mapBack, _ := registry.Command(CMD_MAPB)
+		mapCommand.Config.Next = api.ENDPOINT_LOCATION_AREA + "?offset=20&limit=20"
+		mapCommand.Config.Previous = api.ENDPOINT_LOCATION_AREA + api.PAGINATION
+		if mapBack.Config.Previous != mapCommand.Config.Previous {
+			t.Errorf("map and mapb should share their page")
+		}
+		if again, _ := registry.Command(CMD_MAP); again.Config.Next != mapCommand.Config.Next {
+			t.Errorf("got page %q want %q", again.Config.Next, mapCommand.Config.Next)
+		}
+		if otherMap, _ := other.Command(CMD_MAP); otherMap.Config.Next != api.ENDPOINT_LOCATION_AREA+api.PAGINATION {
+			t.Errorf("another registry should start from the first page, got %q", otherMap.Config.Next)
+		}
+	})
+}
+
+// seed caches an API response under the key a command looks it up with
+func seed(t *testing.T, c *cache.Cache, key string, response any) {
+	t.Helper()
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("error marshalling %s: %v", key, err)
+	}
+	c.Add(key, data)
+}
+
+func TestBrowse(t *testing.T) {
+	duration, _ := time.ParseDuration("1m")
+	Cache := cache.NewCache(duration)
+	Cache.Pokedex = pokedex.NewPokedex()
+	registry := NewRegistry()
+	seed(t, Cache, CMD_REGIONS, api.NamedResources{Results: []api.NamedResource{{Name: "kanto"}, {Name: "johto"}}})
+	seed(t, Cache, CMD_LOCATIONS+" "+api.ENDPOINT_REGION+"johto", api.Region{
+		Name:      "johto",
+		Locations: []api.NamedResource{{Name: "new-bark-town"}, {Name: "cherrygrove-city"}},
+	})
+	seed(t, Cache, CMD_LOCATIONS+" "+api.ENDPOINT_REGION+pokedex.STARTING_REGION, api.Region{
+		Name:      pokedex.STARTING_REGION,
+		Locations: []api.NamedResource{{Name: pokedex.STARTING_LOCATION}},
+	})
+	seed(t, Cache, CMD_AREAS+" "+api.ENDPOINT_LOCATION+"new-bark-town", api.Location{
+		Name:   "new-bark-town",
+		Areas:  []api.NamedResource{{Name: "new-bark-town-area"}},
+		Region: api.NamedResource{Name: "johto"},
+	})
+	seed(t, Cache, CMD_AREAS+" "+api.ENDPOINT_LOCATION+"cherrygrove-city", api.Location{
+		Name:   "cherrygrove-city",
+		Region: api.NamedResource{Name: "johto"},
+	})
+	seed(t, Cache, CMD_VISIT+" "+api.ENDPOINT_LOCATION_AREA+"new-bark-town-area", api.LocationArea{
+		Name:     "new-bark-town-area",
+		Location: api.NamedResource{Name: "new-bark-town"},
+	})
+	seed(t, Cache, CMD_VISIT+" "+api.ENDPOINT_LOCATION_AREA+"lost-area", api.LocationArea{
+		Name:     "lost-area",
+		Location: api.NamedResource{Name: "lost-location"},
+	})
+
+	cases := []struct {
+		name     string
+		command  string
+		words    []string
+		expected []string
+		fails    bool
+	}{
+		{name: "regions", command: CMD_REGIONS, expected: []string{"kanto", "johto"}},
+		{name: "locations of a region", command: CMD_LOCATIONS, words: []string{"Johto"}, expected: []string{"new-bark-town", "cherrygrove-city"}},
+		{name: "locations of the current region", command: CMD_LOCATIONS, expected: []string{pokedex.STARTING_LOCATION}},
+		{name: "unknown region", command: CMD_LOCATIONS, words: []string{"unknown-region"}, fails: true},
+		{name: "areas of a location", command: CMD_AREAS, words: []string{"new-bark-town"}, expected: []string{"new-bark-town-area"}},
+		{name: "location without areas", command: CMD_AREAS, words: []string{"cherrygrove-city"}, expected: []string{}},
+		{name: "unknown location", command: CMD_AREAS, words: []string{"unknown-location"}, fails: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			command, _ := registry.Command(c.command)
+			result, err := command.Call(c.words, Cache)
+			if c.fails {
+				if err == nil {
+					t.Errorf("%s %v should fail", c.command, c.words)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error %q command: %v", c.command, err)
+			}
+			list, ok := result.(NameList)
+			if !ok || !slices.Equal(list.Names, c.expected) {
+				t.Errorf("got %+v want %v", result, c.expected)
+			}
+		})
+	}
+
+	t.Run("visit resolves the location and region", func(t *testing.T) {
+		command, _ := registry.Command(CMD_VISIT)
+		if _, err := command.Call([]string{"new-bark-town-area"}, Cache); err != nil {
+			t.Fatalf("error %q command: %v", CMD_VISIT, err)
+		}
+		want := pokedex.PlayerLocation{Region: "johto", Location: "new-bark-town", LocationArea: "new-bark-town-area"}
+		if Cache.Pokedex.CurrentLocation != want {
+			t.Errorf("got %+v want %+v", Cache.Pokedex.CurrentLocation, want)
+		}
+	})
+
+	t.Run("visit an area of an unknown location", func(t *testing.T) {
+		command, _ := registry.Command(CMD_VISIT)
+		before := Cache.Pokedex.CurrentLocation
+		if _, err := command.Call([]string{"lost-area"}, Cache); err == nil {
+			t.Errorf("an area whose location can't be resolved should fail")
+		}
+		if Cache.Pokedex.CurrentLocation != before {
+			t.Errorf("got %+v, a failed visit should not move", Cache.Pokedex.CurrentLocation)
 		}
 	})
 }
@@ -0,0 +1,911 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charlesaraya/pokedex-go/internal/pokedex"
+	"github.com/charlesaraya/pokedex-go/internal/terminal"
+	"github.com/charlesaraya/pokedex-go/internal/world"
+)
+
+// Result is what a command returns for Run to render, in text or any other format
+type Result interface {
+	Text(w io.Writer) error
+}
+
+// Messages is the result of commands that tell what happens as it happens. Commands
+// write to it like to the terminal, and flush it before asking the player something.
+type Messages struct {
+	text   strings.Builder
+	shown  int
+	live   io.Writer                    // where messages show up as they are written, if rendered as text
+	prompt func(string) (string, error) // asks the player, nil when nobody is there to answer
+}
+
+func (m *Messages) Write(p []byte) (int, error) {
+	return m.text.Write(p)
+}
+
+// Flush shows what was written since the last flush, when rendering as text
+func (m *Messages) Flush() error {
+	if m.live == nil {
+		return nil
+	}
+	return m.Text(m.live)
+}
+
+// Lines returns every message written, one per line
+func (m *Messages) Lines() []string {
+	text := strings.TrimSuffix(m.text.String(), "\n")
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
+
+func (m *Messages) Text(w io.Writer) error {
+	text := m.text.String()[m.shown:]
+	m.shown = m.text.Len()
+	_, err := io.WriteString(w, text)
+	return err
+}
+
+func (m *Messages) Table() ([]string, [][]string) {
+	return []string{"MESSAGE"}, column(m.Lines())
+}
+
+func (m *Messages) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Messages []string `json:"messages"`
+	}{m.Lines()})
+}
+
+// AreaList is a page of location areas of the Pokémon world
+type AreaList struct {
+	Areas    []string `json:"areas"`
+	Next     string   `json:"next"`
+	Previous string   `json:"previous"`
+}
+
+func (a AreaList) Text(w io.Writer) error {
+	terminal.FprettyPrint(w, a.Areas)
+	return nil
+}
+
+func (a AreaList) Table() ([]string, [][]string) {
+	return []string{"AREA"}, column(a.Areas)
+}
+
+// AreaPokemon lists the Pokémon that can be met in a location area
+type AreaPokemon struct {
+	Area    string   `json:"area"`
+	Pokemon []string `json:"pokemon"`
+}
+
+func (a AreaPokemon) Text(w io.Writer) error {
+	terminal.FprettyPrint(w, a.Pokemon)
+	return nil
+}
+
+func (a AreaPokemon) Table() ([]string, [][]string) {
+	return []string{"POKEMON"}, column(a.Pokemon)
+}
+
+// PlayerLocation is where the player stands. As text, it only shows the area, or the
+// location or region when asked.
+type PlayerLocation struct {
+	Region   string `json:"region"`
+	Location string `json:"location"`
+	Area     string `json:"area"`
+	region   bool   // shows the region only
+	location bool   // shows the location only
+}
+
+func (p PlayerLocation) Text(w io.Writer) error {
+	place := p.Area
+	switch {
+	case p.region:
+		place = p.Region
+	case p.location:
+		place = p.Location
+	}
+	_, err := fmt.Fprintln(w, place)
+	return err
+}
+
+func (p PlayerLocation) Table() ([]string, [][]string) {
+	return []string{"REGION", "LOCATION", "AREA"}, [][]string{{p.Region, p.Location, p.Area}}
+}
+
+// EncounterArea is an area where a Pokémon can be met in the wild, and how
+type EncounterArea struct {
+	Region   string `json:"region"`
+	Area     string `json:"area"`
+	Version  string `json:"version"`
+	Method   string `json:"method"`
+	Chance   int    `json:"chance"`
+	MinLevel int    `json:"min_level"`
+	MaxLevel int    `json:"max_level"`
+	Current  bool   `json:"current"`
+}
+
+// PokemonEncounters lists where a Pokémon can be met in the wild
+type PokemonEncounters struct {
+	Pokemon string          `json:"pokemon"`
+	Areas   []EncounterArea `json:"areas"`
+}
+
+func (p PokemonEncounters) Text(w io.Writer) error {
+	if len(p.Areas) == 0 {
+		_, err := fmt.Fprintf(w, "%s can't be found in the wild\n", p.Pokemon)
+		return err
+	}
+	// group the areas by the region they belong to
+	regions := []string{}
+	rowsByRegion := map[string][][]string{}
+	for _, area := range p.Areas {
+		if _, ok := rowsByRegion[area.Region]; !ok {
+			regions = append(regions, area.Region)
+		}
+		marker := ""
+		if area.Current {
+			marker = "*"
+		}
+		rowsByRegion[area.Region] = append(rowsByRegion[area.Region], []string{
+			marker,
+			area.Area,
+			area.Version,
+			area.Method,
+			fmt.Sprintf("%d%%", area.Chance),
+			fmt.Sprintf("%d-%d", area.MinLevel, area.MaxLevel),
+		})
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		fmt.Fprintf(w, "%s:\n", region)
+		terminal.FprintTable(w, []string{"", "AREA", "VERSION", "METHOD", "CHANCE", "LEVELS"}, rowsByRegion[region])
+	}
+	_, err := fmt.Fprintln(w, "(* current area)")
+	return err
+}
+
+func (p PokemonEncounters) Table() ([]string, [][]string) {
+	rows := make([][]string, len(p.Areas))
+	for i, area := range p.Areas {
+		rows[i] = []string{
+			area.Region, area.Area, area.Version, area.Method,
+			strconv.Itoa(area.Chance), strconv.Itoa(area.MinLevel), strconv.Itoa(area.MaxLevel),
+			strconv.FormatBool(area.Current),
+		}
+	}
+	return []string{"REGION", "AREA", "VERSION", "METHOD", "CHANCE", "MIN LEVEL", "MAX LEVEL", "CURRENT"}, rows
+}
+
+// NatureDetails is a nature with the stats it raises and lowers, the same one when neutral
+type NatureDetails struct {
+	Name      string `json:"name"`
+	Increased string `json:"increased"`
+	Decreased string `json:"decreased"`
+}
+
+// StatDetails is how a stat of a Pokémon adds up, Nature marking the stat its nature
+// raises with + and lowers with -
+type StatDetails struct {
+	Stat   string `json:"stat"`
+	Base   int    `json:"base"`
+	IV     int    `json:"iv"`
+	EV     int    `json:"ev"`
+	Final  int    `json:"final"`
+	Nature string `json:"nature,omitempty"`
+}
+
+// PokemonDetails is everything about one of your Pokémon
+type PokemonDetails struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	Species    string        `json:"species"`
+	Shiny      bool          `json:"shiny"`
+	Sprite     string        `json:"sprite"`
+	Level      int           `json:"level"`
+	XP         int           `json:"xp"`
+	HP         int           `json:"hp"`
+	MaxHP      int           `json:"max_hp"`
+	Status     string        `json:"status"`
+	Nature     NatureDetails `json:"nature"`
+	Gender     string        `json:"gender"`
+	Ability    string        `json:"ability"`
+	Friendship int           `json:"friendship"`
+	Height     int           `json:"height"`
+	Weight     int           `json:"weight"`
+	Stats      []StatDetails `json:"stats"`
+	Types      []string      `json:"types"`
+	Moves      []string      `json:"moves"`
+	CaughtAt   time.Time     `json:"caught_at"`
+	CaughtIn   string        `json:"caught_in"`
+	Ball       string        `json:"ball"`
+}
+
+func (p PokemonDetails) Text(w io.Writer) error {
+	fmt.Fprintf(w, "ID: #%d\n", p.ID)
+	fmt.Fprintf(w, "Name: %s\n", p.Name)
+	fmt.Fprintf(w, "Species: %s\n", p.Species)
+	if p.Shiny {
+		fmt.Fprintln(w, "Shiny: yes ✨")
+	}
+	if p.Sprite != "" {
+		fmt.Fprintf(w, "Sprite: %s\n", p.Sprite)
+	}
+	fmt.Fprintf(w, "Level: %v (%v XP)\n", p.Level, p.XP)
+	fmt.Fprintf(w, "HP: %v/%v\n", p.HP, p.MaxHP)
+	fmt.Fprintf(w, "Status: %s\n", p.Status)
+	if p.Nature.Increased != p.Nature.Decreased {
+		fmt.Fprintf(w, "Nature: %s (+%s, -%s)\n", p.Nature.Name, p.Nature.Increased, p.Nature.Decreased)
+	} else {
+		fmt.Fprintf(w, "Nature: %s\n", p.Nature.Name)
+	}
+	fmt.Fprintf(w, "Gender: %s\n", p.Gender)
+	fmt.Fprintf(w, "Ability: %s\n", p.Ability)
+	fmt.Fprintf(w, "Friendship: %d\n", p.Friendship)
+	fmt.Fprintf(w, "Height: %v\n", p.Height)
+	fmt.Fprintf(w, "Weight: %v\n", p.Weight)
+	rows := make([][]string, len(p.Stats))
+	for i, stat := range p.Stats {
+		final := strconv.Itoa(stat.Final)
+		if stat.Nature != "" {
+			final += " " + stat.Nature
+		}
+		rows[i] = []string{"  " + stat.Stat, strconv.Itoa(stat.Base), strconv.Itoa(stat.IV), strconv.Itoa(stat.EV), final}
+	}
+	fmt.Fprintf(w, "Stats:\n")
+	terminal.FprintTable(w, []string{"  STAT", "BASE", "IV", "EV", "FINAL"}, rows)
+	fmt.Fprintf(w, "Types:\n")
+	for _, pokemonType := range p.Types {
+		fmt.Fprintf(w, "  - %s\n", pokemonType)
+	}
+	fmt.Fprintf(w, "Moves:\n")
+	for _, move := range p.Moves {
+		fmt.Fprintf(w, "  - %s\n", move)
+	}
+	_, err := fmt.Fprintf(w, "Caught: %s in %s with a %s\n", p.CaughtAt.Format(time.DateTime), p.CaughtIn, p.Ball)
+	return err
+}
+
+// Table returns a single row, with a column for the final value of each stat
+func (p PokemonDetails) Table() ([]string, [][]string) {
+	header := []string{
+		"ID", "NAME", "SPECIES", "SHINY", "LEVEL", "XP", "HP", "MAX HP", "STATUS", "NATURE", "GENDER",
+		"ABILITY", "FRIENDSHIP", "HEIGHT", "WEIGHT", "TYPES", "MOVES", "CAUGHT AT", "CAUGHT IN", "BALL",
+	}
+	row := []string{
+		strconv.Itoa(p.ID), p.Name, p.Species, strconv.FormatBool(p.Shiny),
+		strconv.Itoa(p.Level), strconv.Itoa(p.XP), strconv.Itoa(p.HP), strconv.Itoa(p.MaxHP), p.Status,
+		p.Nature.Name, p.Gender, p.Ability, strconv.Itoa(p.Friendship), strconv.Itoa(p.Height), strconv.Itoa(p.Weight),
+		strings.Join(p.Types, "/"), strings.Join(p.Moves, "/"), p.CaughtAt.Format(time.RFC3339), p.CaughtIn, p.Ball,
+	}
+	for _, stat := range p.Stats {
+		header = append(header, strings.ToUpper(stat.Stat))
+		row = append(row, strconv.Itoa(stat.Final))
+	}
+	return header, [][]string{row}
+}
+
+// SpeciesList lists the species caught in the Pokédex, in alphabetical order
+type SpeciesList struct {
+	Species []string `json:"species"`
+}
+
+func (s SpeciesList) Text(w io.Writer) error {
+	if len(s.Species) == 0 {
+		_, err := fmt.Fprintln(w, "your Pokedex is empty... Try catch some Pokémons first!")
+		return err
+	}
+	fmt.Fprintln(w, "Your Pokedex:")
+	terminal.FprettyPrint(w, s.Species)
+	return nil
+}
+
+func (s SpeciesList) Table() ([]string, [][]string) {
+	return []string{"SPECIES"}, column(s.Species)
+}
+
+// PokemonSummary is one of your Pokémon in a list
+type PokemonSummary struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Species  string    `json:"species"`
+	Level    int       `json:"level"`
+	Types    []string  `json:"types"`
+	Shiny    bool      `json:"shiny"`
+	CaughtAt time.Time `json:"caught_at"`
+	CaughtIn string    `json:"caught_in"`
+}
+
+// PokemonList lists some of your Pokémon, under a title, or with a message when empty
+type PokemonList struct {
+	Title   string           `json:"-"`
+	Empty   string           `json:"-"`
+	Pokemon []PokemonSummary `json:"pokemon"`
+}
+
+func (p PokemonList) Text(w io.Writer) error {
+	if len(p.Pokemon) == 0 {
+		_, err := fmt.Fprintln(w, p.Empty)
+		return err
+	}
+	rows := make([][]string, len(p.Pokemon))
+	for i, pokemon := range p.Pokemon {
+		name := pokemon.Name
+		if pokemon.Shiny {
+			name += " ✨"
+		}
+		rows[i] = []string{
+			fmt.Sprintf("#%d", pokemon.ID),
+			name,
+			pokemon.Species,
+			strconv.Itoa(pokemon.Level),
+			strings.Join(pokemon.Types, "/"),
+			pokemon.CaughtAt.Format(time.DateTime),
+			pokemon.CaughtIn,
+		}
+	}
+	fmt.Fprintf(w, "%s (%d):\n", p.Title, len(p.Pokemon))
+	terminal.FprintTable(w, []string{"ID", "NAME", "SPECIES", "LEVEL", "TYPES", "CAUGHT", "LOCATION"}, rows)
+	return nil
+}
+
+func (p PokemonList) Table() ([]string, [][]string) {
+	rows := make([][]string, len(p.Pokemon))
+	for i, pokemon := range p.Pokemon {
+		rows[i] = []string{
+			strconv.Itoa(pokemon.ID), pokemon.Name, pokemon.Species, strconv.Itoa(pokemon.Level),
+			strings.Join(pokemon.Types, "/"), strconv.FormatBool(pokemon.Shiny),
+			pokemon.CaughtAt.Format(time.RFC3339), pokemon.CaughtIn,
+		}
+	}
+	return []string{"ID", "NAME", "SPECIES", "LEVEL", "TYPES", "SHINY", "CAUGHT AT", "CAUGHT IN"}, rows
+}
+
+// DexCompletion counts the species of a dex seen and caught
+type DexCompletion struct {
+	Dex    string `json:"dex"`
+	Seen   int    `json:"seen"`
+	Caught int    `json:"caught"`
+	Total  int    `json:"total"`
+}
+
+// DexProgress is the completion of the national dex and of each generation
+type DexProgress struct {
+	Dexes []DexCompletion `json:"dexes"`
+}
+
+func (d DexProgress) Text(w io.Writer) error {
+	percent := func(n, total int) string {
+		if total == 0 {
+			return "0.0%"
+		}
+		return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+	}
+	rows := make([][]string, len(d.Dexes))
+	for i, dex := range d.Dexes {
+		rows[i] = []string{
+			dex.Dex,
+			fmt.Sprintf("%d/%d", dex.Seen, dex.Total), percent(dex.Seen, dex.Total),
+			fmt.Sprintf("%d/%d", dex.Caught, dex.Total), percent(dex.Caught, dex.Total),
+		}
+	}
+	terminal.FprintTable(w, []string{"DEX", "SEEN", "", "CAUGHT", ""}, rows)
+	return nil
+}
+
+func (d DexProgress) Table() ([]string, [][]string) {
+	rows := make([][]string, len(d.Dexes))
+	for i, dex := range d.Dexes {
+		rows[i] = []string{dex.Dex, strconv.Itoa(dex.Seen), strconv.Itoa(dex.Caught), strconv.Itoa(dex.Total)}
+	}
+	return []string{"DEX", "SEEN", "CAUGHT", "TOTAL"}, rows
+}
+
+// MissingEntry is a species not caught yet, whether it was seen, and the areas it
+// can be found in, if known
+type MissingEntry struct {
+	Species string   `json:"species"`
+	Seen    bool     `json:"seen"`
+	FoundIn []string `json:"found_in"`
+}
+
+// MissingSpecies lists the species of a generation not caught yet
+type MissingSpecies struct {
+	Generation string         `json:"generation"`
+	Region     string         `json:"region"`
+	Missing    []MissingEntry `json:"missing"`
+}
+
+func (m MissingSpecies) Text(w io.Writer) error {
+	if len(m.Missing) == 0 {
+		_, err := fmt.Fprintf(w, "You caught every Pokémon from %s!\n", m.Region)
+		return err
+	}
+	rows := make([][]string, len(m.Missing))
+	for i, entry := range m.Missing {
+		seen := ""
+		if entry.Seen {
+			seen = "seen"
+		}
+		where := strings.Join(entry.FoundIn[:min(len(entry.FoundIn), MAX_FOUND_IN)], ", ")
+		if more := len(entry.FoundIn) - MAX_FOUND_IN; more > 0 {
+			where += fmt.Sprintf(" and %d more", more)
+		}
+		rows[i] = []string{entry.Species, seen, where}
+	}
+	fmt.Fprintf(w, "Missing from %s (%s):\n", m.Generation, m.Region)
+	terminal.FprintTable(w, nil, rows)
+	return nil
+}
+
+func (m MissingSpecies) Table() ([]string, [][]string) {
+	rows := make([][]string, len(m.Missing))
+	for i, entry := range m.Missing {
+		rows[i] = []string{entry.Species, strconv.FormatBool(entry.Seen), strings.Join(entry.FoundIn, " ")}
+	}
+	return []string{"SPECIES", "SEEN", "FOUND IN"}, rows
+}
+
+// column turns a list into the rows of a one-column table
+func column(values []string) [][]string {
+	rows := make([][]string, len(values))
+	for i, value := range values {
+		rows[i] = []string{value}
+	}
+	return rows
+}
+
+// CommandHelp describes a command, its arguments and its flags
+type CommandHelp struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+	Args        []Flag `json:"args,omitempty"`
+	Flags       []Flag `json:"flags,omitempty"`
+}
+
+func (h CommandHelp) Text(w io.Writer) error {
+	fmt.Fprintf(w, "usage: %s\n\n%s\n", h.Usage, h.Description)
+	if len(h.Args)+len(h.Flags) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	header, rows := h.Table()
+	terminal.FprintTable(w, header, rows)
+	return nil
+}
+
+func (h CommandHelp) Table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, f := range append(append([]Flag{}, h.Args...), h.Flags...) {
+		kind := f.Type
+		if kind == "" {
+			kind = TYPE_STRING
+		}
+		if len(f.Choices) > 0 {
+			kind = strings.Join(f.Choices, "|")
+		}
+		required := ""
+		if f.Required {
+			required = "yes"
+		}
+		rows = append(rows, []string{f.usage(), kind, f.Default, required, f.Description})
+	}
+	return []string{"ARGUMENT", "TYPE", "DEFAULT", "REQUIRED", "DESCRIPTION"}, rows
+}
+
+// Help lists the commands of the Pokedex, and the flags they all take
+type Help struct {
+	Commands []CommandHelp `json:"commands"`
+	Flags    []Flag        `json:"flags"`
+}
+
+func (h Help) Text(w io.Writer) error {
+	fmt.Fprintln(w, "Welcome to the Pokedex!")
+	fmt.Fprintln(w, "\nusage: <command> [<arguments>] [<flags>]")
+	fmt.Fprintf(w, "\nThese are common Pokedex commands used in various situations:\n\n")
+	for _, command := range h.Commands {
+		fmt.Fprintf(w, "    %s \t%s\n", command.Usage, command.Description)
+	}
+	fmt.Fprintf(w, "\nEvery command also takes:\n\n")
+	for _, flag := range h.Flags {
+		fmt.Fprintf(w, "    %s \t%s\n", flag.usage(), flag.Description)
+	}
+	fmt.Fprintf(w, "\nSee 'help <command>' for the arguments and flags of a command.\n")
+	return nil
+}
+
+func (h Help) Table() ([]string, [][]string) {
+	rows := make([][]string, len(h.Commands))
+	for i, command := range h.Commands {
+		rows[i] = []string{command.Name, command.Usage, command.Description}
+	}
+	return []string{"COMMAND", "USAGE", "DESCRIPTION"}, rows
+}
+
+// NameList is a list of regions, locations or areas
+type NameList struct {
+	Names []string `json:"names"`
+	Empty string   `json:"-"` // shown instead of an empty list
+}
+
+func (n NameList) Text(w io.Writer) error {
+	if len(n.Names) == 0 && n.Empty != "" {
+		_, err := fmt.Fprintln(w, n.Empty)
+		return err
+	}
+	terminal.FprettyPrint(w, n.Names)
+	return nil
+}
+
+func (n NameList) Table() ([]string, [][]string) {
+	return []string{"NAME"}, column(n.Names)
+}
+
+// Exits lists the ways out of the area the player stands in
+type Exits struct {
+	Area  string       `json:"area"`
+	Exits []world.Exit `json:"exits"`
+}
+
+func (e Exits) Text(w io.Writer) error {
+	fmt.Fprintf(w, "You are in %s. Exits:\n", e.Area)
+	rows := make([][]string, len(e.Exits))
+	for i, exit := range e.Exits {
+		tags := ""
+		if len(exit.Tags) > 0 {
+			tags = "(" + strings.Join(exit.Tags, ", ") + ")"
+		}
+		rows[i] = []string{fmt.Sprintf("%d.", i+1), exit.Direction, exit.To, tags}
+	}
+	terminal.FprintTable(w, nil, rows)
+	return nil
+}
+
+func (e Exits) Table() ([]string, [][]string) {
+	return []string{"DIRECTION", "TO", "TAGS"}, exitRows(e.Exits)
+}
+
+// Route is the cheapest way to an area, and for hunts the Pokémon found there
+type Route struct {
+	Pokemon string       `json:"pokemon,omitempty"`
+	To      string       `json:"to"`
+	Steps   []world.Exit `json:"steps"`
+	Cost    int          `json:"cost"`
+}
+
+func (r Route) Text(w io.Writer) error {
+	if r.Pokemon != "" {
+		fmt.Fprintf(w, "%s can be found in %s:\n", r.Pokemon, r.To)
+	}
+	if len(r.Steps) == 0 {
+		_, err := fmt.Fprintln(w, "You are already there!")
+		return err
+	}
+	rows := make([][]string, len(r.Steps))
+	for i, step := range r.Steps {
+		rows[i] = []string{fmt.Sprintf("%d.", i+1), "go " + step.Direction, step.To, strings.Join(step.Tags, ", ")}
+	}
+	terminal.FprintTable(w, nil, rows)
+	_, err := fmt.Fprintf(w, "%d steps, cost %d\n", len(r.Steps), r.Cost)
+	return err
+}
+
+func (r Route) Table() ([]string, [][]string) {
+	return []string{"DIRECTION", "TO", "TAGS"}, exitRows(r.Steps)
+}
+
+func exitRows(exits []world.Exit) [][]string {
+	rows := make([][]string, len(exits))
+	for i, exit := range exits {
+		rows[i] = []string{exit.Direction, exit.To, strings.Join(exit.Tags, ", ")}
+	}
+	return rows
+}
+
+// GameVersion is the game version encounters are looked up in
+type GameVersion struct {
+	Version string `json:"version"`
+}
+
+func (g GameVersion) Text(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s\n", g.Version)
+	return err
+}
+
+func (g GameVersion) Table() ([]string, [][]string) {
+	return []string{"VERSION"}, [][]string{{g.Version}}
+}
+
+// BagItem is an item in the bag and how many of it there are
+type BagItem struct {
+	Item  string `json:"item"`
+	Count int    `json:"count"`
+}
+
+// Bag lists the balls and the other items the player carries
+type Bag struct {
+	Items []BagItem `json:"items"`
+}
+
+func (b Bag) Text(w io.Writer) error {
+	_, rows := b.Table()
+	for _, row := range rows {
+		row[1] = "x" + row[1]
+	}
+	terminal.FprintTable(w, nil, rows)
+	return nil
+}
+
+func (b Bag) Table() ([]string, [][]string) {
+	rows := make([][]string, len(b.Items))
+	for i, item := range b.Items {
+		rows[i] = []string{item.Item, strconv.Itoa(item.Count)}
+	}
+	return []string{"ITEM", "COUNT"}, rows
+}
+
+// ShinyStatus tells the odds of meeting a shiny Pokémon, and how many were caught
+type ShinyStatus struct {
+	Odds       int  `json:"odds"`
+	ShinyCharm bool `json:"shiny_charm"`
+	Caught     int  `json:"caught"`
+}
+
+func (s ShinyStatus) Text(w io.Writer) error {
+	fmt.Fprintf(w, "Shiny odds: 1 in %d\n", s.Odds)
+	if s.ShinyCharm {
+		fmt.Fprintf(w, "Shiny Charm: yes, %d rolls per wild Pokémon\n", pokedex.SHINY_CHARM_ROLLS)
+	} else {
+		fmt.Fprintln(w, "Shiny Charm: no, catch every Pokémon to earn it")
+	}
+	_, err := fmt.Fprintf(w, "Shiny Pokémon caught: %d\n", s.Caught)
+	return err
+}
+
+func (s ShinyStatus) Table() ([]string, [][]string) {
+	return []string{"ODDS", "SHINY CHARM", "CAUGHT"}, [][]string{{strconv.Itoa(s.Odds), strconv.FormatBool(s.ShinyCharm), strconv.Itoa(s.Caught)}}
+}
+
+// PartyMember is one of the player's Pokémon as listed in the party or a box. Its max
+// HP is 0 when its species is not in the Pokedex.
+type PartyMember struct {
+	Slot     int    `json:"slot"`
+	ID       int    `json:"id"`
+	Species  string `json:"species"`
+	Nickname string `json:"nickname,omitempty"`
+	Shiny    bool   `json:"shiny"`
+	Level    int    `json:"level"`
+	HP       int    `json:"hp"`
+	MaxHP    int    `json:"max_hp"`
+	Status   string `json:"status"`
+}
+
+func (p PartyMember) name() string {
+	if p.Nickname != "" {
+		return p.Nickname
+	}
+	return p.Species
+}
+
+func (p PartyMember) hp() string {
+	if p.MaxHP == 0 {
+		return strconv.Itoa(p.HP)
+	}
+	return fmt.Sprintf("%d/%d", p.HP, p.MaxHP)
+}
+
+// row returns the slot, ID, name, level, HP and status of the Pokémon
+func (p PartyMember) row() []string {
+	name := p.name()
+	if p.Nickname != "" {
+		name += " (" + p.Species + ")"
+	}
+	if p.Shiny {
+		name += " ✨"
+	}
+	return []string{strconv.Itoa(p.Slot), fmt.Sprintf("#%d", p.ID), name, fmt.Sprintf("Lv. %d", p.Level), p.hp(), p.Status}
+}
+
+// Text shows the Pokémon on one line, as the lead of the party
+func (p PartyMember) Text(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "#%d %s Lv. %d HP %s %s\n", p.ID, p.name(), p.Level, p.hp(), p.Status)
+	return err
+}
+
+func (p PartyMember) Table() ([]string, [][]string) {
+	return memberTable([]PartyMember{p})
+}
+
+func memberTable(members []PartyMember) ([]string, [][]string) {
+	rows := make([][]string, len(members))
+	for i, member := range members {
+		rows[i] = member.row()
+	}
+	return []string{"SLOT", "ID", "NAME", "LEVEL", "HP", "STATUS"}, rows
+}
+
+// Party lists the Pokémon the player carries
+type Party struct {
+	Members []PartyMember `json:"members"`
+}
+
+func (p Party) Text(w io.Writer) error {
+	if len(p.Members) == 0 {
+		_, err := fmt.Fprintln(w, "Your party is empty... Try catch some Pokémons first!")
+		return err
+	}
+	header, rows := p.Table()
+	header[0] = ""
+	fmt.Fprintf(w, "Your party (%d/%d):\n", len(p.Members), pokedex.PARTY_SIZE)
+	terminal.FprintTable(w, header, rows)
+	return nil
+}
+
+func (p Party) Table() ([]string, [][]string) {
+	return memberTable(p.Members)
+}
+
+// BoxSummary is a PC box and how full it is
+type BoxSummary struct {
+	Box   int    `json:"box"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// BoxList lists the PC boxes
+type BoxList struct {
+	Boxes []BoxSummary `json:"boxes"`
+}
+
+func (b BoxList) Text(w io.Writer) error {
+	rows := make([][]string, len(b.Boxes))
+	for i, box := range b.Boxes {
+		rows[i] = []string{strconv.Itoa(box.Box), box.Name, fmt.Sprintf("%d/%d", box.Count, pokedex.BOX_SIZE)}
+	}
+	terminal.FprintTable(w, []string{"BOX", "NAME", "POKÉMON"}, rows)
+	return nil
+}
+
+func (b BoxList) Table() ([]string, [][]string) {
+	rows := make([][]string, len(b.Boxes))
+	for i, box := range b.Boxes {
+		rows[i] = []string{strconv.Itoa(box.Box), box.Name, strconv.Itoa(box.Count)}
+	}
+	return []string{"BOX", "NAME", "COUNT"}, rows
+}
+
+// BoxContents lists the Pokémon stored in a PC box
+type BoxContents struct {
+	Box     int           `json:"box"`
+	Name    string        `json:"name"`
+	Pokemon []PartyMember `json:"pokemon"`
+}
+
+func (b BoxContents) Text(w io.Writer) error {
+	fmt.Fprintf(w, "%s (%d/%d):\n", b.Name, len(b.Pokemon), pokedex.BOX_SIZE)
+	if len(b.Pokemon) == 0 {
+		_, err := fmt.Fprintln(w, "This box is empty.")
+		return err
+	}
+	header, rows := b.Table()
+	terminal.FprintTable(w, header, rows)
+	return nil
+}
+
+func (b BoxContents) Table() ([]string, [][]string) {
+	return memberTable(b.Pokemon)
+}
+
+// CoverageSource is an attacking type and the party members and moves it comes from
+type CoverageSource struct {
+	Type string   `json:"type"`
+	From []string `json:"from"`
+}
+
+// TypeCoverage is a defending type and the attacking types of the party that hit it
+// super effectively
+type TypeCoverage struct {
+	Type  string           `json:"type"`
+	HitBy []CoverageSource `json:"hit_by"`
+}
+
+// TypeMatchup is how many party members are weak to or resist an attacking type
+type TypeMatchup struct {
+	Type           string `json:"type"`
+	Weak           int    `json:"weak"`
+	Resist         int    `json:"resist"`
+	SharedWeakness bool   `json:"shared_weakness"`
+}
+
+// TeamSuggestion is a boxed Pokémon that hits types the party doesn't hit super
+// effectively, or resists types several party members are weak to
+type TeamSuggestion struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Types   []string `json:"types"`
+	Hits    []string `json:"hits"`
+	Resists []string `json:"resists"`
+}
+
+// TeamAnalysis is the type coverage of the party, its weaknesses and the boxed
+// Pokémon that would close the gaps
+type TeamAnalysis struct {
+	Coverage         []TypeCoverage   `json:"coverage"`
+	Defense          []TypeMatchup    `json:"defense"`
+	Uncovered        []string         `json:"uncovered"`
+	SharedWeaknesses []string         `json:"shared_weaknesses"`
+	Suggestions      []TeamSuggestion `json:"suggestions"`
+}
+
+func (t TeamAnalysis) Text(w io.Writer) error {
+	rows := make([][]string, len(t.Coverage))
+	for i, coverage := range t.Coverage {
+		hitBy := []string{}
+		for _, source := range coverage.HitBy {
+			hitBy = append(hitBy, fmt.Sprintf("%s (%s)", source.Type, strings.Join(source.From, ", ")))
+		}
+		if len(hitBy) == 0 {
+			hitBy = append(hitBy, "-")
+		}
+		rows[i] = []string{coverage.Type, strings.Join(hitBy, "; ")}
+	}
+	fmt.Fprintln(w, "Offensive coverage:")
+	terminal.FprintTable(w, []string{"TYPE", "HIT SUPER EFFECTIVELY BY"}, rows)
+
+	rows = make([][]string, len(t.Defense))
+	for i, matchup := range t.Defense {
+		notes := []string{}
+		if matchup.SharedWeakness {
+			notes = append(notes, "shared weakness")
+		}
+		if matchup.Resist == 0 {
+			notes = append(notes, "no resistance")
+		}
+		rows[i] = []string{matchup.Type, strconv.Itoa(matchup.Weak), strconv.Itoa(matchup.Resist), strings.Join(notes, ", ")}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Defensive matchups:")
+	terminal.FprintTable(w, []string{"TYPE", "WEAK", "RESIST", ""}, rows)
+
+	fmt.Fprintln(w)
+	if len(t.Uncovered) == 0 && len(t.SharedWeaknesses) == 0 {
+		_, err := fmt.Fprintln(w, "Your party hits every type super effectively and shares no weakness!")
+		return err
+	}
+	if len(t.Suggestions) == 0 {
+		_, err := fmt.Fprintln(w, "None of your boxed Pokémon close the gaps of your party.")
+		return err
+	}
+	fmt.Fprintln(w, "Boxed Pokémon that close the gaps:")
+	rows = make([][]string, len(t.Suggestions))
+	for i, s := range t.Suggestions {
+		rows[i] = []string{
+			fmt.Sprintf("#%d", s.ID),
+			s.Name,
+			strings.Join(s.Types, "/"),
+			strings.Join(s.Hits, ", "),
+			strings.Join(s.Resists, ", "),
+		}
+	}
+	terminal.FprintTable(w, []string{"ID", "NAME", "TYPES", "HITS", "RESISTS"}, rows)
+	return nil
+}
+
+func (t TeamAnalysis) Table() ([]string, [][]string) {
+	rows := make([][]string, len(t.Defense))
+	for i, matchup := range t.Defense {
+		rows[i] = []string{matchup.Type, strconv.Itoa(matchup.Weak), strconv.Itoa(matchup.Resist), strconv.FormatBool(matchup.SharedWeakness)}
+	}
+	return []string{"TYPE", "WEAK", "RESIST", "SHARED WEAKNESS"}, rows
+}
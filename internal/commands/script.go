@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charlesaraya/pokedex-go/internal/cache"
+	"github.com/charlesaraya/pokedex-go/internal/render"
+	"github.com/charlesaraya/pokedex-go/internal/terminal"
+)
+
+const (
+	SCRIPT_SEPARATOR string = ";"
+	SCRIPT_COMMENT   string = "#"
+)
+
+// ErrUnknownCommand is returned for a line that doesn't start with a command
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Registry holds the commands of the Pokedex along with the state each of them keeps
+// between calls, like the page of location areas map is at, and how they talk to the
+// player. Every call is parsed from the words it is given, so nothing of a call leaks
+// into the next one.
+type Registry struct {
+	commands map[string]Command
+	output   string                       // the format results are rendered in, unless a command is given -o
+	stdout   io.Writer                    // where results are rendered
+	prompt   func(string) (string, error) // asks the player a question, nil when not interactive
+}
+
+// Invocation is a single call of a command through its registry, with how the command
+// talks to the player during that call only
+type Invocation struct {
+	*Registry
+	command Command                      // the command called
+	live    io.Writer                    // where messages show up as they are written, when rendered as text
+	prompt  func(string) (string, error) // asks the player a question, nil when nobody may answer
+}
+
+// SetOutput sets the default format results are rendered in
+func (r *Registry) SetOutput(format string) error {
+	if !render.Valid(format) {
+		return fmt.Errorf("unknown output format %q, use one of %s", format, strings.Join(render.Formats, ", "))
+	}
+	r.output = format
+	return nil
+}
+
+// SetInteractive sets whether commands ask the player through the terminal, like for a
+// nickname or an evolution. Scripts never do, see RunScript.
+func (r *Registry) SetInteractive(interactive bool) {
+	r.prompt = nil
+	if interactive {
+		r.prompt = terminal.Prompt
+	}
+}
+
+// messages returns the messages of the command called, shown as they are written when
+// rendered as text
+func (inv *Invocation) messages() *Messages {
+	return &Messages{live: inv.live, prompt: inv.prompt}
+}
+
+// Run runs a command with the words it was given as typed, and renders its result in
+// the format asked with -o, or else the one set with SetOutput. What a command did
+// before failing is rendered too.
+func (r *Registry) Run(command Command, words []string, c *cache.Cache) error {
+	return r.run(command, words, c, r.prompt)
+}
+
+// usageError tells what was wrong with what the command called was given, when only
+// the command itself can tell, and how to use it
+func (inv *Invocation) usageError(format string, a ...any) error {
+	return inv.command.usageError(format, a...)
+}
+
+// run is Run with the prompter of the call, nil for the command to never ask
+func (r *Registry) run(command Command, words []string, c *cache.Cache, prompt func(string) (string, error)) error {
+	values, err := command.parse(words)
+	if err != nil {
+		return err
+	}
+	format := outputFormat(values, r.output)
+	inv := &Invocation{Registry: r, command: command, prompt: prompt}
+	if format == render.FORMAT_TEXT {
+		inv.live = r.stdout
+	}
+	result, err := command.run(inv, values, c)
+	if result != nil {
+		if renderErr := render.Render(r.stdout, format, result); renderErr != nil && err == nil {
+			err = renderErr
+		}
+	}
+	return err
+}
+
+// Command returns a command by name
+func (r *Registry) Command(name string) (Command, bool) {
+	command, ok := r.commands[name]
+	return command, ok
+}
+
+// Execute runs a line typed by the player or read from a script. A blank line does
+// nothing.
+func (r *Registry) Execute(line string, c *cache.Cache) error {
+	return r.execute(line, c, r.prompt)
+}
+
+// execute is Execute with the prompter of the call
+func (r *Registry) execute(line string, c *cache.Cache, prompt func(string) (string, error)) error {
+	words := terminal.SplitInput(line)
+	if len(words) == 0 {
+		return nil
+	}
+	name := strings.ToLower(words[0])
+	command, ok := r.Command(name)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownCommand, name)
+	}
+	err := r.run(command, words[1:], c, prompt)
+	if err != nil && !errors.Is(err, ErrExit) {
+		return fmt.Errorf("%s command produced an error: %w", command.Name, err)
+	}
+	return err
+}
+
+// RunScript runs the commands of a script, one per line or separated by ";", skipping
+// lines that start with "#". Like `set -e`, it stops at the first command that fails,
+// and an exit command ends it early. Scripts are never interactive: commands take the
+// default answer instead of asking the player.
+func (r *Registry) RunScript(script io.Reader, c *cache.Cache) error {
+	reader := bufio.NewReader(script)
+	for n := 1; ; n++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed reading line %d: %w", n, err)
+		}
+		if !strings.HasPrefix(strings.TrimSpace(line), SCRIPT_COMMENT) {
+			for _, command := range strings.Split(line, SCRIPT_SEPARATOR) {
+				runErr := r.execute(command, c, nil)
+				if errors.Is(runErr, ErrExit) {
+					return nil
+				}
+				if runErr != nil {
+					return fmt.Errorf("line %d: %w", n, runErr)
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
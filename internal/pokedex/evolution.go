@@ -0,0 +1,72 @@
+package pokedex
+
+import "time"
+
+const (
+	EVOLUTION_LEVEL_UP string = "level-up"
+	EVOLUTION_USE_ITEM string = "use-item"
+	EVOLUTION_TRADE    string = "trade"
+	TIME_DAY           string = "day"
+	TIME_NIGHT         string = "night"
+	NIGHT_STARTS_AT    int    = 20
+	NIGHT_ENDS_AT      int    = 6
+)
+
+// Stones lists the evolution stones a new trainer starts with, one of each
+var Stones = []string{"fire-stone", "water-stone", "thunder-stone", "leaf-stone", "moon-stone"}
+
+// Evolution is one way a species evolves into another, with the conditions to meet.
+// Empty conditions always hold.
+type Evolution struct {
+	Species       string
+	Trigger       string
+	MinLevel      int
+	MinFriendship int
+	TimeOfDay     string
+	Item          string
+	Gender        string
+}
+
+// EvolutionContext is what just happened to a Pokémon that may make it evolve
+type EvolutionContext struct {
+	Trigger string    // level-up, use-item or trade
+	Item    string    // item used on the Pokémon
+	Time    time.Time // when it happened
+}
+
+// TimeOfDay returns whether a time falls during the day or at night
+func TimeOfDay(t time.Time) string {
+	if t.Hour() >= NIGHT_STARTS_AT || t.Hour() < NIGHT_ENDS_AT {
+		return TIME_NIGHT
+	}
+	return TIME_DAY
+}
+
+// Ready reports whether a Pokémon meets the conditions of an evolution
+func (e Evolution) Ready(individual *Individual, ctx EvolutionContext) bool {
+	switch {
+	case e.Trigger != ctx.Trigger:
+		return false
+	case e.Item != "" && e.Item != ctx.Item:
+		return false
+	case e.MinLevel > individual.Level:
+		return false
+	case e.MinFriendship > individual.Friendship:
+		return false
+	case e.TimeOfDay != "" && e.TimeOfDay != TimeOfDay(ctx.Time):
+		return false
+	case e.Gender != "" && e.Gender != individual.Gender:
+		return false
+	}
+	return true
+}
+
+// NextEvolution returns the first evolution a Pokémon is ready for
+func NextEvolution(evolutions []Evolution, individual *Individual, ctx EvolutionContext) (Evolution, bool) {
+	for _, evolution := range evolutions {
+		if evolution.Ready(individual, ctx) {
+			return evolution, true
+		}
+	}
+	return Evolution{}, false
+}
@@ -0,0 +1,85 @@
+package pokedex
+
+const (
+	MAX_LEVEL int = 100
+)
+
+// GrowthCurve holds the total experience a species needs to reach each level, indexed
+// by level, as given by its growth rate
+type GrowthCurve []int
+
+// Experience returns the total experience needed to reach a level
+func (g GrowthCurve) Experience(level int) int {
+	if len(g) == 0 {
+		return 0
+	}
+	return g[min(max(level, 1), len(g)-1)]
+}
+
+// Level returns the level reached with a total experience
+func (g GrowthCurve) Level(xp int) int {
+	level := 1
+	for l := 2; l < len(g); l++ {
+		if g[l] > xp {
+			break
+		}
+		level = l
+	}
+	return level
+}
+
+// GainExperience adds experience to a Pokémon and returns the levels it grew, which
+// the caller turns into stats and moves. Pokémon never drop below the experience of
+// their level, so those saved without any catch up first. Each level grown also
+// raises friendship, less so the friendlier the Pokémon already is.
+func (i *Individual) GainExperience(xp int, curve GrowthCurve) []int {
+	i.XP = min(max(i.XP, curve.Experience(i.Level))+xp, curve.Experience(MAX_LEVEL))
+	levels := []int{}
+	for level := i.Level + 1; level <= curve.Level(i.XP); level++ {
+		levels = append(levels, level)
+		switch {
+		case i.Friendship < 100:
+			i.Friendship += 5
+		case i.Friendship < 200:
+			i.Friendship += 3
+		default:
+			i.Friendship = min(i.Friendship+2, MAX_FRIENDSHIP)
+		}
+	}
+	if len(levels) > 0 {
+		i.Level = levels[len(levels)-1]
+	}
+	return levels
+}
+
+// LearnMove teaches a move to a Pokémon with a free move slot, forgetting the move
+// `forget` otherwise. It reports whether the move was learnt.
+func (i *Individual) LearnMove(move, forget string) bool {
+	for _, known := range i.Moves {
+		if known == move {
+			return false
+		}
+	}
+	if len(i.Moves) < MAX_MOVES {
+		i.Moves = append(i.Moves, move)
+		return true
+	}
+	for j, known := range i.Moves {
+		if known == forget {
+			i.Moves[j] = move
+			return true
+		}
+	}
+	return false
+}
+
+// GainEffort adds the effort values a defeated Pokémon yields to a Pokémon's EVs, up
+// to MAX_EV per stat and MAX_TOTAL_EV overall
+func (i *Individual) GainEffort(defeated Pokemon) {
+	for _, stat := range defeated.Stats {
+		gain := min(stat.Effort, MAX_EV-i.EVs.Get(stat.Stat.Name), MAX_TOTAL_EV-i.EVs.Total())
+		if gain > 0 {
+			i.EVs.Add(stat.Stat.Name, gain)
+		}
+	}
+}
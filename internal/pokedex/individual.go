@@ -0,0 +1,292 @@
+package pokedex
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DEFAULT_LEVEL       int    = 5
+	MAX_IV              int    = 31
+	MAX_EV              int    = 252
+	MAX_TOTAL_EV        int    = 510
+	NATURE_BOOST        int    = 10 // in percent
+	MAX_FRIENDSHIP      int    = 255
+	MAX_NICKNAME_LENGTH int    = 12
+	NICKNAME_SYMBOLS    string = ".-'♂♀" // allowed in nicknames besides letters and digits
+	SHINY_ODDS          int    = 4096
+	SHINY_CHARM         string = "shiny-charm"
+	SHINY_CHARM_ROLLS   int    = 3 // shiny rolls per wild Pokémon with the Shiny Charm
+	MAX_MOVES           int    = 4
+	GENDER_MALE         string = "male"
+	GENDER_FEMALE       string = "female"
+	GENDER_GENDERLESS   string = "genderless"
+)
+
+// Natures lists the 25 Pokémon natures
+var Natures = []string{
+	"hardy", "lonely", "brave", "adamant", "naughty",
+	"bold", "docile", "relaxed", "impish", "lax",
+	"timid", "hasty", "serious", "jolly", "naive",
+	"modest", "mild", "quiet", "bashful", "rash",
+	"calm", "gentle", "sassy", "careful", "quirky",
+}
+
+// Stats holds a value per stat, e.g. the IVs or EVs of a Pokémon
+type Stats struct {
+	HP             int `json:"hp"`
+	Attack         int `json:"attack"`
+	Defense        int `json:"defense"`
+	SpecialAttack  int `json:"special-attack"`
+	SpecialDefense int `json:"special-defense"`
+	Speed          int `json:"speed"`
+}
+
+// Get returns the value of a stat by its PokéAPI name
+func (s Stats) Get(name string) int {
+	switch name {
+	case STAT_HP:
+		return s.HP
+	case STAT_ATTACK:
+		return s.Attack
+	case STAT_DEFENSE:
+		return s.Defense
+	case STAT_SP_ATTACK:
+		return s.SpecialAttack
+	case STAT_SP_DEFENSE:
+		return s.SpecialDefense
+	case STAT_SPEED:
+		return s.Speed
+	}
+	return 0
+}
+
+// Add adds to the value of a stat by its PokéAPI name
+func (s *Stats) Add(name string, n int) {
+	switch name {
+	case STAT_HP:
+		s.HP += n
+	case STAT_ATTACK:
+		s.Attack += n
+	case STAT_DEFENSE:
+		s.Defense += n
+	case STAT_SP_ATTACK:
+		s.SpecialAttack += n
+	case STAT_SP_DEFENSE:
+		s.SpecialDefense += n
+	case STAT_SPEED:
+		s.Speed += n
+	}
+}
+
+// Total returns the sum of all stats
+func (s Stats) Total() int {
+	return s.HP + s.Attack + s.Defense + s.SpecialAttack + s.SpecialDefense + s.Speed
+}
+
+// Nature raises one stat and lowers another by NATURE_BOOST percent. Neutral natures
+// raise and lower nothing.
+type Nature struct {
+	Name      string
+	Increased string
+	Decreased string
+}
+
+// Modifier returns the percentage a nature applies to a stat
+func (n Nature) Modifier(stat string) int {
+	switch {
+	case n.Increased == n.Decreased:
+		return 100
+	case stat == n.Increased:
+		return 100 + NATURE_BOOST
+	case stat == n.Decreased:
+		return 100 - NATURE_BOOST
+	}
+	return 100
+}
+
+// Individual is one Pokémon, wild or owned by the player. Its species data lives in
+// the Pokédex entry of its species.
+type Individual struct {
+	ID             int
+	Species        string
+	Nickname       string
+	Level          int
+	XP             int
+	IVs            Stats
+	EVs            Stats
+	Nature         string
+	Gender         string
+	Shiny          bool
+	Ability        string
+	Friendship     int
+	HP             int
+	Status         Status
+	Moves          []string
+	CaughtAt       time.Time
+	CaughtLocation PlayerLocation
+	Ball           string
+}
+
+// RollGender rolls the gender of a Pokémon of a species with a gender rate, the chance
+// of being female in eighths, -1 if genderless. roll returns a random number in [0, n),
+// e.g. rand.Intn.
+func RollGender(genderRate int, roll func(n int) int) string {
+	switch {
+	case genderRate < 0:
+		return GENDER_GENDERLESS
+	case roll(8) < genderRate:
+		return GENDER_FEMALE
+	}
+	return GENDER_MALE
+}
+
+// NewIndividual rolls a new Pokémon of a species at a level: random IVs, nature,
+// gender and ability, knowing the last moves it learnt by levelling up. genderRate is
+// the chance of being female in eighths, -1 if genderless. roll returns a random
+// number in [0, n), e.g. rand.Intn. HP is left to the caller.
+func NewIndividual(pokemon Pokemon, level int, genderRate int, roll func(n int) int) *Individual {
+	individual := &Individual{
+		Species: pokemon.Name,
+		Level:   level,
+		IVs: Stats{
+			HP:             roll(MAX_IV + 1),
+			Attack:         roll(MAX_IV + 1),
+			Defense:        roll(MAX_IV + 1),
+			SpecialAttack:  roll(MAX_IV + 1),
+			SpecialDefense: roll(MAX_IV + 1),
+			Speed:          roll(MAX_IV + 1),
+		},
+		Nature: Natures[roll(len(Natures))],
+		Gender: RollGender(genderRate, roll),
+	}
+	abilities := []string{}
+	for _, ability := range pokemon.Abilities {
+		if !ability.IsHidden {
+			abilities = append(abilities, ability.Ability.Name)
+		}
+	}
+	if len(abilities) > 0 {
+		individual.Ability = abilities[roll(len(abilities))]
+	}
+	moves := pokemon.LevelUpMoves(level)
+	individual.Moves = moves[max(len(moves)-MAX_MOVES, 0):]
+	return individual
+}
+
+// RollShiny rolls whether a wild Pokémon is shiny: 1 in ShinyOdds, with a
+// SHINY_CHARM_ROLLS times better chance when the Shiny Charm is in the bag. roll
+// returns a random number in [0, n), e.g. rand.Intn.
+func (p *Pokedex) RollShiny(roll func(n int) int) bool {
+	p.Mu.RLock()
+	odds := p.ShinyOdds
+	rolls := 1
+	if p.Bag[SHINY_CHARM] > 0 {
+		rolls = SHINY_CHARM_ROLLS
+	}
+	p.Mu.RUnlock()
+	if odds <= 0 {
+		odds = SHINY_ODDS
+	}
+	for range rolls {
+		if roll(odds) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Shinies returns the player's shiny Pokémon sorted by ID
+func (p *Pokedex) Shinies() []*Individual {
+	shinies := []*Individual{}
+	for _, individual := range p.Individuals() {
+		if individual.Shiny {
+			shinies = append(shinies, individual)
+		}
+	}
+	return shinies
+}
+
+// Name returns the nickname of a Pokémon, or its species when it has none
+func (i *Individual) Name() string {
+	if i.Nickname != "" {
+		return i.Nickname
+	}
+	return i.Species
+}
+
+// AddIndividual gives a Pokémon a unique ID and adds it to the player's Pokémon
+func (p *Pokedex) AddIndividual(individual *Individual) int {
+	p.Mu.Lock()
+	defer p.Mu.Unlock()
+	if p.Pokemons == nil {
+		p.Pokemons = make(map[int]*Individual)
+	}
+	p.NextID++
+	individual.ID = p.NextID
+	p.Pokemons[individual.ID] = individual
+	return individual.ID
+}
+
+// GetIndividual returns one of the player's Pokémon by ID
+func (p *Pokedex) GetIndividual(id int) (*Individual, bool) {
+	p.Mu.RLock()
+	individual, ok := p.Pokemons[id]
+	p.Mu.RUnlock()
+	return individual, ok
+}
+
+// Individuals returns the player's Pokémon sorted by ID
+func (p *Pokedex) Individuals() []*Individual {
+	p.Mu.RLock()
+	individuals := make([]*Individual, 0, len(p.Pokemons))
+	for _, individual := range p.Pokemons {
+		individuals = append(individuals, individual)
+	}
+	p.Mu.RUnlock()
+	sort.Slice(individuals, func(i, j int) bool { return individuals[i].ID < individuals[j].ID })
+	return individuals
+}
+
+// Find returns one of the player's Pokémon by ID, or the first one with a nickname or
+// of a species. Nicknames are matched regardless of case.
+func (p *Pokedex) Find(query string) (*Individual, bool) {
+	if id, err := strconv.Atoi(strings.TrimPrefix(query, "#")); err == nil {
+		return p.GetIndividual(id)
+	}
+	individuals := p.Individuals()
+	for _, individual := range individuals {
+		if individual.Nickname != "" && strings.EqualFold(individual.Nickname, query) {
+			return individual, true
+		}
+	}
+	for _, individual := range individuals {
+		if individual.Species == query {
+			return individual, true
+		}
+	}
+	return nil, false
+}
+
+// ValidateNickname checks a nickname is at most MAX_NICKNAME_LENGTH letters, digits
+// and NICKNAME_SYMBOLS. Nicknames can't look like an ID, so they don't hide one.
+func ValidateNickname(nickname string) error {
+	length := utf8.RuneCountInString(nickname)
+	if length == 0 || length > MAX_NICKNAME_LENGTH {
+		return fmt.Errorf("nicknames are 1 to %d characters long", MAX_NICKNAME_LENGTH)
+	}
+	for _, r := range nickname {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(NICKNAME_SYMBOLS, r) {
+			return fmt.Errorf("nicknames can't contain %q", r)
+		}
+	}
+	if _, err := strconv.Atoi(nickname); err == nil {
+		return fmt.Errorf("nicknames can't be a number")
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package pokedex
 
 import (
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -9,23 +11,126 @@ const (
 	STARTING_REGION        string = "kanto"
 	STARTING_LOCATION      string = "pallet-town"
 	STARTING_LOCATION_AREA string = "pallet-town-area"
+	STARTING_VERSION       string = "red"
+	STAT_HP                string = "hp"
+	STAT_ATTACK            string = "attack"
+	STAT_DEFENSE           string = "defense"
+	STAT_SP_ATTACK         string = "special-attack"
+	STAT_SP_DEFENSE        string = "special-defense"
+	STAT_SPEED             string = "speed"
+	LEARN_METHOD_LEVEL_UP  string = "level-up"
 )
 
 type Pokemon struct {
-	Name       string        `json:"name"`
-	Height     int           `json:"height"`
-	Weight     int           `json:"weight"`
-	Experience int           `json:"base_experience"`
-	Url        string        `json:"url"`
-	Stats      []PokemonStat `json:"stats"`
-	Types      []PokemonType `json:"types"`
+	Name       string           `json:"name"`
+	Height     int              `json:"height"`
+	Weight     int              `json:"weight"`
+	Experience int              `json:"base_experience"`
+	Url        string           `json:"url"`
+	Stats      []PokemonStat    `json:"stats"`
+	Types      []PokemonType    `json:"types"`
+	Moves      []PokemonMove    `json:"moves"`
+	Abilities  []PokemonAbility `json:"abilities"`
+	Species    struct {
+		Name string `json:"name"`
+		Url  string `json:"url"`
+	} `json:"species"`
+	Sprites struct {
+		FrontDefault string `json:"front_default"`
+		FrontShiny   string `json:"front_shiny"`
+	} `json:"sprites"`
+}
+
+// Sprite returns the URL of the sprite of a Pokémon, in its shiny colours or not
+func (p Pokemon) Sprite(shiny bool) string {
+	if shiny {
+		return p.Sprites.FrontShiny
+	}
+	return p.Sprites.FrontDefault
+}
+
+// BaseStat returns the base value of a stat (hp, attack, speed...), or 0 if unknown
+func (p Pokemon) BaseStat(name string) int {
+	for _, stat := range p.Stats {
+		if stat.Stat.Name == name {
+			return stat.Base
+		}
+	}
+	return 0
 }
 
 type PokemonStat struct {
 	Stat struct {
 		Name string `json:"name"`
 	} `json:"stat"`
-	Base int `json:"base_stat"`
+	Base   int `json:"base_stat"`
+	Effort int `json:"effort"`
+}
+
+type PokemonMove struct {
+	Move struct {
+		Name string `json:"name"`
+		Url  string `json:"url"`
+	} `json:"move"`
+	VersionGroupDetails []struct {
+		LevelLearnedAt  int `json:"level_learned_at"`
+		MoveLearnMethod struct {
+			Name string `json:"name"`
+		} `json:"move_learn_method"`
+	} `json:"version_group_details"`
+}
+
+// LearntAt returns the lowest level a move is learnt at by levelling up, -1 if it isn't
+func (m PokemonMove) LearntAt() int {
+	learntAt := -1
+	for _, detail := range m.VersionGroupDetails {
+		if detail.MoveLearnMethod.Name != LEARN_METHOD_LEVEL_UP {
+			continue
+		}
+		if learntAt == -1 || detail.LevelLearnedAt < learntAt {
+			learntAt = detail.LevelLearnedAt
+		}
+	}
+	return learntAt
+}
+
+// LevelUpMoves returns the moves a Pokémon learns by levelling up to a level, in the
+// order it learns them
+func (p Pokemon) LevelUpMoves(level int) []string {
+	type learnt struct {
+		name  string
+		level int
+	}
+	moves := []learnt{}
+	for _, move := range p.Moves {
+		if learntAt := move.LearntAt(); learntAt != -1 && learntAt <= level {
+			moves = append(moves, learnt{name: move.Move.Name, level: learntAt})
+		}
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].level < moves[j].level })
+	names := make([]string, len(moves))
+	for i, move := range moves {
+		names[i] = move.name
+	}
+	return names
+}
+
+// MovesLearntAt returns the moves a Pokémon learns when it reaches a level
+func (p Pokemon) MovesLearntAt(level int) []string {
+	names := []string{}
+	for _, move := range p.Moves {
+		if move.LearntAt() == level {
+			names = append(names, move.Move.Name)
+		}
+	}
+	return names
+}
+
+type PokemonAbility struct {
+	Ability struct {
+		Name string `json:"name"`
+	} `json:"ability"`
+	IsHidden bool `json:"is_hidden"`
 }
 
 type PokemonType struct {
@@ -34,40 +139,113 @@ type PokemonType struct {
 	} `json:"type"`
 }
 
+// Status is the non-volatile status condition of a Pokémon (sleep, paralysis, burn,
+// poison or freeze), empty when healthy
+type Status struct {
+	Condition string
+	Turns     int // turns left asleep
+}
+
 type PokedexEntry struct {
 	CatchedAt time.Time
+	SeenAt    time.Time
+	SeenIn    string // location area the species was first seen in
 	Pokemon   Pokemon
 }
 
+// Caught reports whether the species has been caught, not just seen
+func (e *PokedexEntry) Caught() bool {
+	return !e.CatchedAt.IsZero()
+}
+
+// SpeciesName returns the name of the species of the entry's Pokémon
+func (e *PokedexEntry) SpeciesName() string {
+	if e.Pokemon.Species.Name != "" {
+		return e.Pokemon.Species.Name
+	}
+	return e.Pokemon.Name
+}
+
 type PlayerLocation struct {
 	Region       string
 	Location     string
 	LocationArea string
 }
 
+// Bag holds the quantity of each item the player carries
+type Bag map[string]int
+
+// NewBag returns the bag every trainer starts their journey with
+func NewBag() Bag {
+	bag := Bag{
+		"poke-ball":   10,
+		"great-ball":  5,
+		"ultra-ball":  3,
+		"master-ball": 1,
+		"net-ball":    3,
+		"dusk-ball":   3,
+		"quick-ball":  3,
+		"timer-ball":  3,
+	}
+	for _, stone := range Stones {
+		bag[stone] = 1
+	}
+	return bag
+}
+
 type Pokedex struct {
 	PokedexEntries  map[string]*PokedexEntry
 	CurrentLocation PlayerLocation
+	Version         string
+	Bag             Bag
+	Pokemons        map[int]*Individual
+	NextID          int
+	Party           []int
+	Boxes           []*Box
+	ShinyOdds       int // 1 in ShinyOdds wild Pokémon are shiny, SHINY_ODDS when unset
 	Mu              sync.RWMutex
 }
 
 func NewPokedex() *Pokedex {
 	var pokedex *Pokedex = &Pokedex{
 		PokedexEntries: make(map[string]*PokedexEntry),
+		Pokemons:       make(map[int]*Individual),
 		CurrentLocation: PlayerLocation{
 			Region:       STARTING_REGION,
 			Location:     STARTING_LOCATION,
 			LocationArea: STARTING_LOCATION_AREA,
 		},
+		Version: STARTING_VERSION,
+		Bag:     NewBag(),
+		Boxes:   NewBoxes(),
 	}
 	return pokedex
 }
 
+// Add marks a Pokémon as caught
 func (p *Pokedex) Add(pokemon Pokemon) {
 	p.Mu.Lock()
-	p.PokedexEntries[pokemon.Name] = &PokedexEntry{
-		CatchedAt: time.Now(),
-		Pokemon:   pokemon,
+	entry, ok := p.PokedexEntries[pokemon.Name]
+	if !ok {
+		entry = &PokedexEntry{SeenAt: time.Now()}
+		p.PokedexEntries[pokemon.Name] = entry
+	}
+	if !entry.Caught() {
+		entry.CatchedAt = time.Now()
+	}
+	entry.Pokemon = pokemon
+	p.Mu.Unlock()
+}
+
+// MarkSeen marks a Pokémon as seen in a location area
+func (p *Pokedex) MarkSeen(pokemon Pokemon, locationArea string) {
+	p.Mu.Lock()
+	if _, ok := p.PokedexEntries[pokemon.Name]; !ok {
+		p.PokedexEntries[pokemon.Name] = &PokedexEntry{
+			SeenAt:  time.Now(),
+			SeenIn:  locationArea,
+			Pokemon: pokemon,
+		}
 	}
 	p.Mu.Unlock()
 }
@@ -79,12 +257,72 @@ func (p *Pokedex) Get(key string) (*PokedexEntry, bool) {
 	return pokedexEntry, ok
 }
 
+// GetAll returns the names of the caught Pokémon
 func (p *Pokedex) GetAll() []string {
 	pokemonNames := []string{}
 	p.Mu.RLock()
-	for key := range p.PokedexEntries {
-		pokemonNames = append(pokemonNames, key)
+	for key, entry := range p.PokedexEntries {
+		if entry.Caught() {
+			pokemonNames = append(pokemonNames, key)
+		}
 	}
 	p.Mu.RUnlock()
 	return pokemonNames
 }
+
+// Progress counts how many of the given species have been seen and caught
+func (p *Pokedex) Progress(species []string) (seen int, caught int) {
+	seenSpecies, caughtSpecies := p.speciesIndex()
+	for _, name := range species {
+		if _, ok := seenSpecies[name]; ok {
+			seen++
+		}
+		if caughtSpecies[name] {
+			caught++
+		}
+	}
+	return seen, caught
+}
+
+// Missing returns the given species that have not been caught yet, mapped to the
+// location area they were seen in, empty when never seen
+func (p *Pokedex) Missing(species []string) ([]string, map[string]string) {
+	seenSpecies, caughtSpecies := p.speciesIndex()
+	missing := []string{}
+	for _, name := range species {
+		if !caughtSpecies[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing, seenSpecies
+}
+
+// speciesIndex returns the seen species mapped to where they were seen, and the
+// caught species
+func (p *Pokedex) speciesIndex() (map[string]string, map[string]bool) {
+	seen := map[string]string{}
+	caught := map[string]bool{}
+	p.Mu.RLock()
+	for _, entry := range p.PokedexEntries {
+		name := entry.SpeciesName()
+		if where, ok := seen[name]; !ok || where == "" {
+			seen[name] = entry.SeenIn
+		}
+		if entry.Caught() {
+			caught[name] = true
+		}
+	}
+	p.Mu.RUnlock()
+	return seen, caught
+}
+
+// UseItem takes one unit of an item out of the bag
+func (p *Pokedex) UseItem(item string) error {
+	p.Mu.Lock()
+	defer p.Mu.Unlock()
+	if p.Bag[item] <= 0 {
+		return fmt.Errorf("you are out of %s", item)
+	}
+	p.Bag[item]--
+	return nil
+}
@@ -1,6 +1,10 @@
 package pokedex
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
 
 func TestPokedex(t *testing.T) {
 	var pokedex = NewPokedex()
@@ -33,3 +37,359 @@ func TestPokedex(t *testing.T) {
 		}
 	}
 }
+
+func TestBag(t *testing.T) {
+	var pokedex = NewPokedex()
+	pokedex.Bag = Bag{"great-ball": 1}
+
+	if err := pokedex.UseItem("great-ball"); err != nil {
+		t.Errorf("Error [Pokedex.UseItem]: failed to use an item in the bag: %v", err)
+	}
+	if got := pokedex.Bag["great-ball"]; got != 0 {
+		t.Errorf("Error [Pokedex.UseItem]: got %d great-balls left, want 0", got)
+	}
+	if err := pokedex.UseItem("great-ball"); err == nil {
+		t.Errorf("Error [Pokedex.UseItem]: shouldn't use an item that ran out")
+	}
+	if err := pokedex.UseItem("ultra-ball"); err == nil {
+		t.Errorf("Error [Pokedex.UseItem]: shouldn't use an item not in the bag")
+	}
+}
+
+func TestLevelUpMoves(t *testing.T) {
+	var pikachu Pokemon
+	data := `{"name": "pikachu", "moves": [
+		{"move": {"name": "thunderbolt"}, "version_group_details": [{"level_learned_at": 0, "move_learn_method": {"name": "machine"}}]},
+		{"move": {"name": "quick-attack"}, "version_group_details": [{"level_learned_at": 16, "move_learn_method": {"name": "level-up"}}, {"level_learned_at": 6, "move_learn_method": {"name": "level-up"}}]},
+		{"move": {"name": "thunder-shock"}, "version_group_details": [{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}}]},
+		{"move": {"name": "thunder"}, "version_group_details": [{"level_learned_at": 41, "move_learn_method": {"name": "level-up"}}]}
+	]}`
+	if err := json.Unmarshal([]byte(data), &pikachu); err != nil {
+		t.Fatalf("Error [json.Unmarshal]: %v", err)
+	}
+	got := pikachu.LevelUpMoves(10)
+	want := []string{"thunder-shock", "quick-attack"}
+	if len(got) != len(want) {
+		t.Fatalf("Error [Pokemon.LevelUpMoves]: got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Error [Pokemon.LevelUpMoves]: got %v want %v", got, want)
+		}
+	}
+	if got := pikachu.MovesLearntAt(6); len(got) != 1 || got[0] != "quick-attack" {
+		t.Errorf("Error [Pokemon.MovesLearntAt]: got %v want [quick-attack]", got)
+	}
+	if got := pikachu.MovesLearntAt(16); len(got) != 0 {
+		t.Errorf("Error [Pokemon.MovesLearntAt]: got %v want none", got)
+	}
+}
+
+func TestIndividuals(t *testing.T) {
+	var pokedex = NewPokedex()
+	pikachu := Pokemon{Name: "pikachu", Abilities: []PokemonAbility{{IsHidden: true}, {}}}
+	pikachu.Abilities[0].Ability.Name = "lightning-rod"
+	pikachu.Abilities[1].Ability.Name = "static"
+
+	// every roll picks the highest value
+	roll := func(n int) int { return n - 1 }
+	first := NewIndividual(pikachu, 5, 4, roll)
+	if first.IVs.Get(STAT_SPEED) != MAX_IV || first.Nature != "quirky" || first.Gender != GENDER_MALE || first.Ability != "static" {
+		t.Errorf("Error [NewIndividual]: unexpected individual %+v", first)
+	}
+	if genderless := NewIndividual(pikachu, 5, -1, roll); genderless.Gender != GENDER_GENDERLESS {
+		t.Errorf("Error [NewIndividual]: got gender %s want %s", genderless.Gender, GENDER_GENDERLESS)
+	}
+
+	// catching a second pikachu keeps both
+	firstID := pokedex.AddIndividual(first)
+	secondID := pokedex.AddIndividual(NewIndividual(pikachu, 7, 4, roll))
+	if firstID == secondID {
+		t.Errorf("Error [Pokedex.AddIndividual]: IDs should be unique, got %d twice", firstID)
+	}
+	if got := len(pokedex.Individuals()); got != 2 {
+		t.Errorf("Error [Pokedex.Individuals]: got %d Pokémon want 2", got)
+	}
+	cases := []struct {
+		query    string
+		expected int
+	}{
+		{query: "pikachu", expected: firstID},
+		{query: "2", expected: secondID},
+		{query: "#2", expected: secondID},
+		{query: "sparky", expected: secondID},
+	}
+	second, _ := pokedex.GetIndividual(secondID)
+	second.Nickname = "Sparky"
+	for _, c := range cases {
+		individual, ok := pokedex.Find(c.query)
+		if !ok || individual.ID != c.expected {
+			t.Errorf("Error [Pokedex.Find]: %q should find #%d", c.query, c.expected)
+		}
+	}
+	if _, ok := pokedex.Find("raichu"); ok {
+		t.Errorf("Error [Pokedex.Find]: shouldn't find an uncaught species")
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var pokedex = NewPokedex()
+	pokedex.MarkSeen(Pokemon{Name: "pidgey"}, "kanto-route-1-area")
+	pokedex.MarkSeen(Pokemon{Name: "rattata"}, "kanto-route-1-area")
+	pokedex.Add(Pokemon{Name: "rattata"})
+	pokedex.MarkSeen(Pokemon{Name: "rattata"}, "kanto-route-2-area")
+
+	if got := pokedex.GetAll(); len(got) != 1 || got[0] != "rattata" {
+		t.Errorf("Error [Pokedex.GetAll]: got %v want only the caught rattata", got)
+	}
+	entry, _ := pokedex.Get("rattata")
+	if entry.SeenIn != "kanto-route-1-area" || !entry.Caught() {
+		t.Errorf("Error [Pokedex.Add]: catching should keep where rattata was first seen, got %+v", entry)
+	}
+	species := []string{"pidgey", "rattata", "spearow"}
+	seen, caught := pokedex.Progress(species)
+	if seen != 2 || caught != 1 {
+		t.Errorf("Error [Pokedex.Progress]: got %d seen %d caught want 2 seen 1 caught", seen, caught)
+	}
+	missing, seenIn := pokedex.Missing(species)
+	if len(missing) != 2 || missing[0] != "pidgey" || missing[1] != "spearow" {
+		t.Errorf("Error [Pokedex.Missing]: got %v want [pidgey spearow]", missing)
+	}
+	if seenIn["pidgey"] != "kanto-route-1-area" || seenIn["spearow"] != "" {
+		t.Errorf("Error [Pokedex.Missing]: got %v", seenIn)
+	}
+}
+
+func TestStorage(t *testing.T) {
+	var pokedex = NewPokedex()
+	for i := 0; i < PARTY_SIZE+1; i++ {
+		id := pokedex.AddIndividual(&Individual{Species: "rattata"})
+		box, err := pokedex.Store(id)
+		want := PARTY
+		if i >= PARTY_SIZE {
+			want = 1
+		}
+		if err != nil || box != want {
+			t.Errorf("Error [Pokedex.Store]: #%d went to box %d want %d (%v)", id, box, want, err)
+		}
+	}
+	cases := []struct {
+		name     string
+		action   func() error
+		expected error
+	}{
+		{name: "withdraw into a full party", action: func() error { return pokedex.Withdraw(7) }, expected: ErrPartyFull},
+		{name: "deposit", action: func() error { _, err := pokedex.Deposit(1); return err }},
+		{name: "deposit a boxed Pokémon", action: func() error { _, err := pokedex.Deposit(1); return err }, expected: ErrInBox},
+		{name: "withdraw", action: func() error { return pokedex.Withdraw(7) }},
+		{name: "withdraw a party member", action: func() error { return pokedex.Withdraw(7) }, expected: ErrInParty},
+		{name: "swap party and box", action: func() error { return pokedex.Swap(2, 1) }},
+		{name: "swap with itself", action: func() error { return pokedex.Swap(2, 2) }, expected: ErrSameLocation},
+		{name: "lead", action: func() error { return pokedex.SetLead(7) }},
+		{name: "lead with a boxed Pokémon", action: func() error { return pokedex.SetLead(2) }, expected: ErrInBox},
+		{name: "release", action: func() error { return pokedex.Release(2) }},
+		{name: "release a released Pokémon", action: func() error { return pokedex.Release(2) }, expected: ErrNotOwned},
+		{name: "open a missing box", action: func() error { _, err := pokedex.Box(BOX_COUNT + 1); return err }, expected: ErrNoSuchBox},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.action(); err != c.expected {
+				t.Errorf("Error: got %v want %v", err, c.expected)
+			}
+		})
+	}
+	if lead, ok := pokedex.Lead(); !ok || lead.ID != 7 {
+		t.Errorf("Error [Pokedex.Lead]: got %v want #7", lead)
+	}
+	if got := pokedex.Party; len(got) != PARTY_SIZE || got[1] != 1 {
+		t.Errorf("Error [Pokedex.Party]: got %v want #7 then #1 in a full party", got)
+	}
+	box, _ := pokedex.Box(1)
+	if box.Count() != 0 {
+		t.Errorf("Error [Box.Count]: got %d Pokémon in box 1 want 0", box.Count())
+	}
+
+	// the last party member can't leave
+	var alone = NewPokedex()
+	id := alone.AddIndividual(&Individual{Species: "pikachu"})
+	alone.Store(id)
+	if _, err := alone.Deposit(id); err != ErrLastMember {
+		t.Errorf("Error [Pokedex.Deposit]: got %v want %v", err, ErrLastMember)
+	}
+	if err := alone.Release(id); err != ErrLastMember {
+		t.Errorf("Error [Pokedex.Release]: got %v want %v", err, ErrLastMember)
+	}
+}
+
+func TestExperience(t *testing.T) {
+	// medium-fast growth: level cubed
+	curve := make(GrowthCurve, MAX_LEVEL+1)
+	for level := 1; level <= MAX_LEVEL; level++ {
+		curve[level] = level * level * level
+	}
+	if got := curve.Level(215); got != 5 {
+		t.Errorf("Error [GrowthCurve.Level]: got level %d want 5", got)
+	}
+	cases := []struct {
+		name     string
+		level    int
+		xp       int
+		gain     int
+		expected []int
+	}{
+		{name: "no level up", level: 5, xp: 125, gain: 50, expected: []int{}},
+		{name: "one level up", level: 5, xp: 125, gain: 91, expected: []int{6}},
+		{name: "several level ups", level: 5, xp: 200, gain: 400, expected: []int{6, 7, 8}},
+		{name: "saved without experience", level: 5, xp: 0, gain: 91, expected: []int{6}},
+		{name: "max level", level: MAX_LEVEL, xp: curve[MAX_LEVEL], gain: 1000, expected: []int{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			individual := &Individual{Level: c.level, XP: c.xp}
+			levels := individual.GainExperience(c.gain, curve)
+			if len(levels) != len(c.expected) {
+				t.Fatalf("Error [Individual.GainExperience]: got levels %v want %v", levels, c.expected)
+			}
+			for i := range levels {
+				if levels[i] != c.expected[i] {
+					t.Errorf("Error [Individual.GainExperience]: got levels %v want %v", levels, c.expected)
+				}
+			}
+			if individual.XP > curve[MAX_LEVEL] || curve.Level(individual.XP) != individual.Level {
+				t.Errorf("Error [Individual.GainExperience]: level %d doesn't match %d XP", individual.Level, individual.XP)
+			}
+		})
+	}
+
+	individual := &Individual{Moves: []string{"tackle", "growl", "tail-whip"}}
+	if !individual.LearnMove("thunder-shock", "") || len(individual.Moves) != MAX_MOVES {
+		t.Errorf("Error [Individual.LearnMove]: should learn into a free slot, got %v", individual.Moves)
+	}
+	if individual.LearnMove("quick-attack", "") {
+		t.Errorf("Error [Individual.LearnMove]: should not learn without forgetting a move")
+	}
+	if !individual.LearnMove("quick-attack", "growl") || individual.Moves[1] != "quick-attack" {
+		t.Errorf("Error [Individual.LearnMove]: should replace growl, got %v", individual.Moves)
+	}
+}
+
+func TestEffort(t *testing.T) {
+	pidgey := Pokemon{Name: "pidgey", Stats: []PokemonStat{{Base: 56, Effort: 1}, {Base: 40, Effort: 0}}}
+	pidgey.Stats[0].Stat.Name = STAT_SPEED
+	pidgey.Stats[1].Stat.Name = STAT_HP
+	cases := []struct {
+		name     string
+		evs      Stats
+		expected Stats
+	}{
+		{name: "gain", evs: Stats{}, expected: Stats{Speed: 1}},
+		{name: "stat cap", evs: Stats{Speed: MAX_EV}, expected: Stats{Speed: MAX_EV}},
+		{name: "total cap", evs: Stats{HP: MAX_EV, Attack: MAX_EV, Speed: 6}, expected: Stats{HP: MAX_EV, Attack: MAX_EV, Speed: 6}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			individual := &Individual{EVs: c.evs}
+			individual.GainEffort(pidgey)
+			if individual.EVs != c.expected {
+				t.Errorf("Error [Individual.GainEffort]: got %+v want %+v", individual.EVs, c.expected)
+			}
+		})
+	}
+	adamant := Nature{Name: "adamant", Increased: STAT_ATTACK, Decreased: STAT_SP_ATTACK}
+	if adamant.Modifier(STAT_ATTACK) != 110 || adamant.Modifier(STAT_SP_ATTACK) != 90 || adamant.Modifier(STAT_SPEED) != 100 {
+		t.Errorf("Error [Nature.Modifier]: unexpected modifiers for %+v", adamant)
+	}
+}
+
+func TestEvolution(t *testing.T) {
+	day := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2026, 1, 1, 22, 0, 0, 0, time.UTC)
+	evolutions := []Evolution{
+		{Species: "espeon", Trigger: EVOLUTION_LEVEL_UP, MinFriendship: 160, TimeOfDay: TIME_DAY},
+		{Species: "umbreon", Trigger: EVOLUTION_LEVEL_UP, MinFriendship: 160, TimeOfDay: TIME_NIGHT},
+		{Species: "vaporeon", Trigger: EVOLUTION_USE_ITEM, Item: "water-stone"},
+	}
+	cases := []struct {
+		name       string
+		friendship int
+		ctx        EvolutionContext
+		expected   string
+	}{
+		{name: "friendly at day", friendship: 200, ctx: EvolutionContext{Trigger: EVOLUTION_LEVEL_UP, Time: day}, expected: "espeon"},
+		{name: "friendly at night", friendship: 200, ctx: EvolutionContext{Trigger: EVOLUTION_LEVEL_UP, Time: night}, expected: "umbreon"},
+		{name: "not friendly enough", friendship: 70, ctx: EvolutionContext{Trigger: EVOLUTION_LEVEL_UP, Time: day}},
+		{name: "water stone", ctx: EvolutionContext{Trigger: EVOLUTION_USE_ITEM, Item: "water-stone", Time: night}, expected: "vaporeon"},
+		{name: "fire stone", ctx: EvolutionContext{Trigger: EVOLUTION_USE_ITEM, Item: "fire-stone", Time: night}},
+		{name: "trade", friendship: 255, ctx: EvolutionContext{Trigger: EVOLUTION_TRADE, Time: day}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			eevee := &Individual{Species: "eevee", Level: 20, Friendship: c.friendship}
+			evolution, _ := NextEvolution(evolutions, eevee, c.ctx)
+			if evolution.Species != c.expected {
+				t.Errorf("Error [NextEvolution]: got %q want %q", evolution.Species, c.expected)
+			}
+		})
+	}
+
+	// levelling up makes a Pokémon friendlier
+	curve := GrowthCurve{0, 0, 10, 20, 30}
+	individual := &Individual{Level: 1, Friendship: 98}
+	individual.GainExperience(30, curve)
+	if individual.Friendship != 109 {
+		t.Errorf("Error [Individual.GainExperience]: got friendship %d want 109", individual.Friendship)
+	}
+}
+
+func TestValidateNickname(t *testing.T) {
+	cases := []struct {
+		nickname string
+		valid    bool
+	}{
+		{nickname: "Sparky", valid: true},
+		{nickname: "Mr.Mime-2", valid: true},
+		{nickname: "Nidoran♀", valid: true},
+		{nickname: "Ñandú", valid: true},
+		{nickname: "", valid: false},
+		{nickname: "Thunderstruck!", valid: false},
+		{nickname: "ABCDEFGHIJKLM", valid: false},
+		{nickname: "Big Guy", valid: false},
+		{nickname: "42", valid: false},
+	}
+	for _, c := range cases {
+		if err := ValidateNickname(c.nickname); (err == nil) != c.valid {
+			t.Errorf("Error [ValidateNickname]: %q got %v want valid %v", c.nickname, err, c.valid)
+		}
+	}
+}
+
+func TestShiny(t *testing.T) {
+	var pokedex = NewPokedex()
+	rolls := 0
+	// only the third roll hits
+	roll := func(n int) int {
+		rolls++
+		if n != SHINY_ODDS || rolls < 3 {
+			return 1
+		}
+		return 0
+	}
+	if pokedex.RollShiny(roll) || rolls != 1 {
+		t.Errorf("Error [Pokedex.RollShiny]: should roll once without the Shiny Charm, rolled %d times", rolls)
+	}
+	pokedex.Bag[SHINY_CHARM] = 1
+	rolls = 0
+	if !pokedex.RollShiny(roll) || rolls != SHINY_CHARM_ROLLS {
+		t.Errorf("Error [Pokedex.RollShiny]: should roll %d times with the Shiny Charm, rolled %d times", SHINY_CHARM_ROLLS, rolls)
+	}
+	pokedex.ShinyOdds = 1
+	if !pokedex.RollShiny(func(n int) int { return n - 1 }) {
+		t.Errorf("Error [Pokedex.RollShiny]: every Pokémon should be shiny with odds of 1")
+	}
+
+	pokedex.AddIndividual(&Individual{Species: "pidgey"})
+	pokedex.AddIndividual(&Individual{Species: "rattata", Shiny: true})
+	if shinies := pokedex.Shinies(); len(shinies) != 1 || shinies[0].Species != "rattata" {
+		t.Errorf("Error [Pokedex.Shinies]: got %v want the rattata", shinies)
+	}
+}
@@ -0,0 +1,273 @@
+package pokedex
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	PARTY_SIZE int = 6
+	BOX_SIZE   int = 30
+	BOX_COUNT  int = 12
+	// PARTY is the box number of the party, PC boxes are numbered from 1
+	PARTY int = 0
+)
+
+var (
+	ErrNotOwned     = errors.New("you don't have that Pokémon")
+	ErrPartyFull    = errors.New("your party is full")
+	ErrPCFull       = errors.New("your PC boxes are full")
+	ErrLastMember   = errors.New("it's the last Pokémon in your party")
+	ErrInParty      = errors.New("it's already in your party")
+	ErrInBox        = errors.New("it's already in the PC")
+	ErrNoSuchBox    = errors.New("there is no such box")
+	ErrSameLocation = errors.New("it can't swap places with itself")
+)
+
+// Box is a PC box, where each empty slot holds a zero ID
+type Box struct {
+	Name  string
+	Slots [BOX_SIZE]int
+}
+
+// Count returns how many Pokémon are in the box
+func (b *Box) Count() int {
+	count := 0
+	for _, id := range b.Slots {
+		if id != 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// NewBoxes returns the empty PC boxes a new trainer starts with
+func NewBoxes() []*Box {
+	boxes := make([]*Box, BOX_COUNT)
+	for i := range boxes {
+		boxes[i] = &Box{Name: fmt.Sprintf("Box %d", i+1)}
+	}
+	return boxes
+}
+
+// locate returns the box and slot holding a Pokémon, where box PARTY is the party
+func (p *Pokedex) locate(id int) (box int, slot int, ok bool) {
+	for i, partyID := range p.Party {
+		if partyID == id {
+			return PARTY, i, true
+		}
+	}
+	for i, b := range p.Boxes {
+		for j, boxID := range b.Slots {
+			if boxID == id {
+				return i + 1, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// freeSlot returns the first empty slot in the PC
+func (p *Pokedex) freeSlot() (box int, slot int, ok bool) {
+	for i, b := range p.Boxes {
+		for j, boxID := range b.Slots {
+			if boxID == 0 {
+				return i + 1, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// Locate returns the box holding one of the player's Pokémon, PARTY when it's in the party
+func (p *Pokedex) Locate(id int) (int, bool) {
+	p.Mu.RLock()
+	defer p.Mu.RUnlock()
+	box, _, ok := p.locate(id)
+	return box, ok
+}
+
+// Full reports whether there is no room left for another Pokémon
+func (p *Pokedex) Full() bool {
+	p.Mu.RLock()
+	defer p.Mu.RUnlock()
+	_, _, ok := p.freeSlot()
+	return len(p.Party) >= PARTY_SIZE && !ok
+}
+
+// Store places a new Pokémon in the party, or in the PC when the party is full,
+// and returns the box it went to
+func (p *Pokedex) Store(id int) (int, error) {
+	p.Mu.Lock()
+	defer p.Mu.Unlock()
+	if len(p.Party) < PARTY_SIZE {
+		p.Party = append(p.Party, id)
+		return PARTY, nil
+	}
+	box, slot, ok := p.freeSlot()
+	if !ok {
+		return 0, ErrPCFull
+	}
+	p.Boxes[box-1].Slots[slot] = id
+	return box, nil
+}
+
+// Deposit moves a Pokémon from the party to the first free slot in the PC,
+// and returns the box it went to
+func (p *Pokedex) Deposit(id int) (int, error) {
+	p.Mu.Lock()
+	defer p.Mu.Unlock()
+	box, slot, ok := p.locate(id)
+	switch {
+	case !ok:
+		return 0, ErrNotOwned
+	case box != PARTY:
+		return 0, ErrInBox
+	case len(p.Party) == 1:
+		return 0, ErrLastMember
+	}
+	box, boxSlot, ok := p.freeSlot()
+	if !ok {
+		return 0, ErrPCFull
+	}
+	p.Boxes[box-1].Slots[boxSlot] = id
+	p.Party = append(p.Party[:slot], p.Party[slot+1:]...)
+	return box, nil
+}
+
+// Withdraw moves a Pokémon from the PC to the end of the party
+func (p *Pokedex) Withdraw(id int) error {
+	p.Mu.Lock()
+	defer p.Mu.Unlock()
+	box, slot, ok := p.locate(id)
+	switch {
+	case !ok:
+		return ErrNotOwned
+	case box == PARTY:
+		return ErrInParty
+	case len(p.Party) >= PARTY_SIZE:
+		return ErrPartyFull
+	}
+	p.Boxes[box-1].Slots[slot] = 0
+	p.Party = append(p.Party, id)
+	return nil
+}
+
+// Swap exchanges the places of two Pokémon, wherever they are
+func (p *Pokedex) Swap(a, b int) error {
+	p.Mu.Lock()
+	defer p.Mu.Unlock()
+	if a == b {
+		return ErrSameLocation
+	}
+	boxA, slotA, okA := p.locate(a)
+	boxB, slotB, okB := p.locate(b)
+	if !okA || !okB {
+		return ErrNotOwned
+	}
+	p.place(boxA, slotA, b)
+	p.place(boxB, slotB, a)
+	return nil
+}
+
+// place puts a Pokémon in a slot of the party or of a box
+func (p *Pokedex) place(box, slot, id int) {
+	if box == PARTY {
+		p.Party[slot] = id
+		return
+	}
+	p.Boxes[box-1].Slots[slot] = id
+}
+
+// Release sets one of the player's Pokémon free for good
+func (p *Pokedex) Release(id int) error {
+	p.Mu.Lock()
+	defer p.Mu.Unlock()
+	box, slot, ok := p.locate(id)
+	switch {
+	case !ok:
+		return ErrNotOwned
+	case box == PARTY && len(p.Party) == 1:
+		return ErrLastMember
+	case box == PARTY:
+		p.Party = append(p.Party[:slot], p.Party[slot+1:]...)
+	default:
+		p.Boxes[box-1].Slots[slot] = 0
+	}
+	delete(p.Pokemons, id)
+	return nil
+}
+
+// SetLead moves a party member to the front of the party
+func (p *Pokedex) SetLead(id int) error {
+	p.Mu.Lock()
+	defer p.Mu.Unlock()
+	box, slot, ok := p.locate(id)
+	switch {
+	case !ok:
+		return ErrNotOwned
+	case box != PARTY:
+		return ErrInBox
+	}
+	copy(p.Party[1:slot+1], p.Party[:slot])
+	p.Party[0] = id
+	return nil
+}
+
+// Lead returns the Pokémon at the front of the party
+func (p *Pokedex) Lead() (*Individual, bool) {
+	p.Mu.RLock()
+	defer p.Mu.RUnlock()
+	if len(p.Party) == 0 {
+		return nil, false
+	}
+	individual, ok := p.Pokemons[p.Party[0]]
+	return individual, ok
+}
+
+// PartyMembers returns the Pokémon in the party, in order
+func (p *Pokedex) PartyMembers() []*Individual {
+	p.Mu.RLock()
+	defer p.Mu.RUnlock()
+	members := make([]*Individual, 0, len(p.Party))
+	for _, id := range p.Party {
+		if individual, ok := p.Pokemons[id]; ok {
+			members = append(members, individual)
+		}
+	}
+	return members
+}
+
+// Box returns a PC box by its number, starting from 1
+func (p *Pokedex) Box(n int) (*Box, error) {
+	p.Mu.RLock()
+	defer p.Mu.RUnlock()
+	if n < 1 || n > len(p.Boxes) {
+		return nil, ErrNoSuchBox
+	}
+	return p.Boxes[n-1], nil
+}
+
+// RenameBox gives a PC box a new name
+func (p *Pokedex) RenameBox(n int, name string) error {
+	p.Mu.Lock()
+	defer p.Mu.Unlock()
+	if n < 1 || n > len(p.Boxes) {
+		return ErrNoSuchBox
+	}
+	p.Boxes[n-1].Name = name
+	return nil
+}
+
+// Organize puts every Pokémon that is in neither the party nor the PC into storage,
+// lowest ID first, as saves from older versions have no party
+func (p *Pokedex) Organize() {
+	if p.Boxes == nil {
+		p.Boxes = NewBoxes()
+	}
+	for _, individual := range p.Individuals() {
+		if _, ok := p.Locate(individual.ID); !ok {
+			p.Store(individual.ID)
+		}
+	}
+}
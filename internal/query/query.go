@@ -0,0 +1,273 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	OP_MATCH    string = ":"
+	OP_EQUAL    string = "="
+	OP_NOT      string = "!="
+	OP_GREATER  string = ">"
+	OP_LESS     string = "<"
+	OP_AT_LEAST string = ">="
+	OP_AT_MOST  string = "<="
+	KEY_SORT    string = "sort"
+	KEY_LIMIT   string = "limit"
+	// NAME_FIELD is the field matched by terms with no operator
+	NAME_FIELD  string = "name"
+	DATE_LAYOUT string = time.DateOnly
+)
+
+// operators are tried longest first, so that >= isn't read as >
+var operators = []string{OP_AT_LEAST, OP_AT_MOST, OP_NOT, OP_MATCH, OP_EQUAL, OP_GREATER, OP_LESS}
+
+// Record is an item of a list. Field returns the values of one of its fields, several
+// for fields like types, and false when the item has no such field. Values are
+// compared as numbers, dates or booleans when both sides read as such, and as
+// case-insensitive strings otherwise.
+type Record interface {
+	Field(name string) ([]string, bool)
+}
+
+// Filter keeps the records with a value of Field that compares to Value with Op
+type Filter struct {
+	Field string
+	Op    string
+	Value string
+}
+
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// Query is a list of filters, a sort order and a limit, 0 meaning no limit
+type Query struct {
+	Filters []Filter
+	Sort    []SortKey
+	Limit   int
+}
+
+// Parse reads a query from terms like `type:fire`, `stat.speed>90`, `sort:-weight`
+// or `limit:10`. A term with no operator matches names containing it.
+func Parse(terms []string) (Query, error) {
+	q := Query{}
+	for _, term := range terms {
+		field, op, value := split(term)
+		switch {
+		case op == "":
+			q.Filters = append(q.Filters, Filter{Field: NAME_FIELD, Op: OP_MATCH, Value: "*" + term + "*"})
+		case field == "" || value == "":
+			return q, fmt.Errorf("invalid term %q", term)
+		case field == KEY_SORT && op == OP_MATCH:
+			for _, key := range strings.Split(value, ",") {
+				sortKey := SortKey{Field: strings.TrimPrefix(key, "-"), Desc: strings.HasPrefix(key, "-")}
+				if sortKey.Field == "" {
+					return q, fmt.Errorf("invalid sort %q", value)
+				}
+				q.Sort = append(q.Sort, sortKey)
+			}
+		case field == KEY_LIMIT && op == OP_MATCH:
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 {
+				return q, fmt.Errorf("invalid limit %q", value)
+			}
+			q.Limit = limit
+		default:
+			q.Filters = append(q.Filters, Filter{Field: field, Op: op, Value: value})
+		}
+	}
+	return q, nil
+}
+
+// split cuts a term around its first operator
+func split(term string) (field, op, value string) {
+	first := -1
+	for _, operator := range operators {
+		i := strings.Index(term, operator)
+		if i != -1 && (first == -1 || i < first || (i == first && len(operator) > len(op))) {
+			first, op = i, operator
+		}
+	}
+	if first == -1 {
+		return term, "", ""
+	}
+	return term[:first], op, term[first+len(op):]
+}
+
+// Apply returns the records matching every filter, sorted and limited
+func Apply[R Record](records []R, q Query) ([]R, error) {
+	matched := []R{}
+	for _, record := range records {
+		ok, err := q.Match(record)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, record)
+		}
+	}
+	var sortErr error
+	sort.SliceStable(matched, func(i, j int) bool {
+		for _, key := range q.Sort {
+			a, okA := matched[i].Field(key.Field)
+			b, okB := matched[j].Field(key.Field)
+			if !okA || !okB {
+				sortErr = fmt.Errorf("unknown field %q", key.Field)
+				return false
+			}
+			c := compare(first(a), first(b))
+			if c == 0 {
+				continue
+			}
+			return (c < 0) != key.Desc
+		}
+		return false
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched, nil
+}
+
+// Match reports whether a record passes every filter
+func (q Query) Match(record Record) (bool, error) {
+	for _, filter := range q.Filters {
+		values, ok := record.Field(filter.Field)
+		if !ok {
+			return false, fmt.Errorf("unknown field %q", filter.Field)
+		}
+		if !filter.match(values) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// match reports whether any of the values passes the filter, or, for OP_NOT, whether
+// none of them equals the filter value
+func (f Filter) match(values []string) bool {
+	if f.Op == OP_NOT {
+		for _, value := range values {
+			if compare(value, f.Value) == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	for _, value := range values {
+		if f.Op == OP_MATCH && strings.Contains(f.Value, "*") {
+			if wildcard(strings.ToLower(value), strings.ToLower(f.Value)) {
+				return true
+			}
+			continue
+		}
+		c := compare(value, f.Value)
+		switch f.Op {
+		case OP_MATCH, OP_EQUAL:
+			if c == 0 {
+				return true
+			}
+		case OP_GREATER:
+			if c > 0 {
+				return true
+			}
+		case OP_LESS:
+			if c < 0 {
+				return true
+			}
+		case OP_AT_LEAST:
+			if c >= 0 {
+				return true
+			}
+		case OP_AT_MOST:
+			if c <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wildcard matches a value against a pattern where * stands for any characters
+func wildcard(value, pattern string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if i == len(parts)-2 {
+			return strings.HasSuffix(value, part)
+		}
+		j := strings.Index(value, part)
+		if j == -1 {
+			return false
+		}
+		value = value[j+len(part):]
+	}
+	return true
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// compare compares two values as numbers, dates or booleans when both read as the same
+// kind, and as case-insensitive strings otherwise. Dates compare to the day when one
+// of them has no time.
+func compare(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, ok := parseDate(a); ok {
+		if y, ok := parseDate(b); ok {
+			if len(a) == len(DATE_LAYOUT) || len(b) == len(DATE_LAYOUT) {
+				return strings.Compare(x.Format(DATE_LAYOUT), y.Format(DATE_LAYOUT))
+			}
+			return x.Compare(y)
+		}
+	}
+	if x, err := strconv.ParseBool(a); err == nil {
+		if y, err := strconv.ParseBool(b); err == nil {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// parseDate reads a local date, or a date and time in RFC 3339
+func parseDate(s string) (time.Time, bool) {
+	if t, err := time.ParseInLocation(DATE_LAYOUT, s, time.Local); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(time.Local), true
+	}
+	return time.Time{}, false
+}
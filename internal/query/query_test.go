@@ -0,0 +1,140 @@
+package query
+
+import (
+	"testing"
+)
+
+type pokemon struct {
+	name   string
+	types  []string
+	speed  string
+	caught string
+	shiny  string
+}
+
+func (p pokemon) Field(name string) ([]string, bool) {
+	switch name {
+	case "name":
+		return []string{p.name}, true
+	case "type":
+		return p.types, true
+	case "stat.speed":
+		return []string{p.speed}, true
+	case "caught":
+		return []string{p.caught}, true
+	case "shiny":
+		return []string{p.shiny}, true
+	}
+	return nil, false
+}
+
+var pokemons = []pokemon{
+	{name: "charmander", types: []string{"fire"}, speed: "65", caught: "2026-01-01T10:00:00Z", shiny: "false"},
+	{name: "ponyta", types: []string{"fire"}, speed: "90", caught: "2026-02-10T10:00:00Z", shiny: "true"},
+	{name: "pikachu", types: []string{"electric"}, speed: "90", caught: "2025-12-24T10:00:00Z", shiny: "false"},
+	{name: "charizard", types: []string{"fire", "flying"}, speed: "100", caught: "2026-03-05T10:00:00Z", shiny: "false"},
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		terms    []string
+		expected Query
+		fails    bool
+	}{
+		{
+			terms: []string{"type:fire", "stat.speed>=90", "sort:-weight,name", "limit:10"},
+			expected: Query{
+				Filters: []Filter{{Field: "type", Op: OP_MATCH, Value: "fire"}, {Field: "stat.speed", Op: OP_AT_LEAST, Value: "90"}},
+				Sort:    []SortKey{{Field: "weight", Desc: true}, {Field: "name"}},
+				Limit:   10,
+			},
+		},
+		{
+			terms:    []string{"char", "ball!=poke-ball"},
+			expected: Query{Filters: []Filter{{Field: NAME_FIELD, Op: OP_MATCH, Value: "*char*"}, {Field: "ball", Op: OP_NOT, Value: "poke-ball"}}},
+		},
+		{terms: []string{"type:"}, fails: true},
+		{terms: []string{">90"}, fails: true},
+		{terms: []string{"limit:none"}, fails: true},
+		{terms: []string{"sort:-"}, fails: true},
+	}
+	for _, c := range cases {
+		got, err := Parse(c.terms)
+		if c.fails {
+			if err == nil {
+				t.Errorf("Error [Parse]: %q should fail", c.terms)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error [Parse]: %q failed: %v", c.terms, err)
+			continue
+		}
+		if len(got.Filters) != len(c.expected.Filters) || len(got.Sort) != len(c.expected.Sort) || got.Limit != c.expected.Limit {
+			t.Fatalf("Error [Parse]: got %+v want %+v", got, c.expected)
+		}
+		for i := range got.Filters {
+			if got.Filters[i] != c.expected.Filters[i] {
+				t.Errorf("Error [Parse]: got filter %+v want %+v", got.Filters[i], c.expected.Filters[i])
+			}
+		}
+		for i := range got.Sort {
+			if got.Sort[i] != c.expected.Sort[i] {
+				t.Errorf("Error [Parse]: got sort %+v want %+v", got.Sort[i], c.expected.Sort[i])
+			}
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name     string
+		terms    []string
+		expected []string
+		fails    bool
+	}{
+		{name: "type", terms: []string{"type:fire"}, expected: []string{"charmander", "ponyta", "charizard"}},
+		{name: "any of several types", terms: []string{"type:flying"}, expected: []string{"charizard"}},
+		{name: "none of several types", terms: []string{"type!=fire"}, expected: []string{"pikachu"}},
+		{name: "numbers", terms: []string{"stat.speed>65", "stat.speed<100"}, expected: []string{"ponyta", "pikachu"}},
+		{name: "dates", terms: []string{"caught>2026-01-01"}, expected: []string{"ponyta", "charizard"}},
+		{name: "same day", terms: []string{"caught:2026-01-01"}, expected: []string{"charmander"}},
+		{name: "booleans", terms: []string{"shiny:true"}, expected: []string{"ponyta"}},
+		{name: "name", terms: []string{"CHAR"}, expected: []string{"charmander", "charizard"}},
+		{name: "wildcard", terms: []string{"name:p*a"}, expected: []string{"ponyta"}},
+		{name: "sort and limit", terms: []string{"sort:-stat.speed,name", "limit:3"}, expected: []string{"charizard", "pikachu", "ponyta"}},
+		{name: "sort by date", terms: []string{"sort:caught"}, expected: []string{"pikachu", "charmander", "ponyta", "charizard"}},
+		{name: "unknown filter field", terms: []string{"color:red"}, fails: true},
+		{name: "unknown sort field", terms: []string{"sort:color"}, fails: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, err := Parse(c.terms)
+			if err != nil {
+				t.Fatalf("Error [Parse]: %v", err)
+			}
+			got, err := Apply(pokemons, q)
+			if c.fails {
+				if err == nil {
+					t.Errorf("Error [Apply]: %q should fail", c.terms)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error [Apply]: %v", err)
+			}
+			names := make([]string, len(got))
+			for i, p := range got {
+				names[i] = p.name
+			}
+			if len(names) != len(c.expected) {
+				t.Fatalf("Error [Apply]: got %v want %v", names, c.expected)
+			}
+			for i := range names {
+				if names[i] != c.expected[i] {
+					t.Errorf("Error [Apply]: got %v want %v", names, c.expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charlesaraya/pokedex-go/internal/terminal"
+)
+
+const (
+	FORMAT_TEXT string = "text"
+	FORMAT_JSON string = "json"
+	FORMAT_CSV  string = "csv"
+	FORMAT_YAML string = "yaml"
+)
+
+// Formats lists the output formats results can be rendered in
+var Formats = []string{FORMAT_TEXT, FORMAT_JSON, FORMAT_CSV, FORMAT_YAML}
+
+// Texter is a result with its own human-readable text
+type Texter interface {
+	Text(w io.Writer) error
+}
+
+// Tabler is a result that reads as a table, used for CSV and as its default text
+type Tabler interface {
+	Table() (header []string, rows [][]string)
+}
+
+// Valid reports whether a format is one of Formats
+func Valid(format string) bool {
+	return slices.Contains(Formats, format)
+}
+
+// Render writes a result in a format: text as the result describes itself, JSON and
+// YAML from its JSON encoding, or CSV from its table
+func Render(w io.Writer, format string, result any) error {
+	switch format {
+	case FORMAT_TEXT:
+		return renderText(w, result)
+	case FORMAT_JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case FORMAT_CSV:
+		return renderCSV(w, result)
+	case FORMAT_YAML:
+		return renderYAML(w, result)
+	}
+	return fmt.Errorf("unknown output format %q, use one of %s", format, strings.Join(Formats, ", "))
+}
+
+func renderText(w io.Writer, result any) error {
+	switch r := result.(type) {
+	case Texter:
+		return r.Text(w)
+	case Tabler:
+		header, rows := r.Table()
+		terminal.FprintTable(w, header, rows)
+		return nil
+	}
+	_, err := fmt.Fprintln(w, result)
+	return err
+}
+
+func renderCSV(w io.Writer, result any) error {
+	tabler, ok := result.(Tabler)
+	if !ok {
+		return fmt.Errorf("this output can't be written as %s", FORMAT_CSV)
+	}
+	header, rows := tabler.Table()
+	writer := csv.NewWriter(w)
+	if len(header) > 0 {
+		writer.Write(header)
+	}
+	writer.WriteAll(rows)
+	return writer.Error()
+}
+
+// field is a key and value of a YAML mapping, which keeps the order of the JSON object
+// it comes from
+type field struct {
+	key   string
+	value any
+}
+
+// renderYAML writes a result as YAML, going through its JSON encoding so that both
+// formats share field names and order
+func renderYAML(w io.Writer, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("error: marshal operation failed: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return fmt.Errorf("error: unmarshal operation failed: %w", err)
+	}
+	var b bytes.Buffer
+	switch v := value.(type) {
+	case []field:
+		if len(v) == 0 {
+			b.WriteString("{}\n")
+		}
+	case []any:
+		if len(v) == 0 {
+			b.WriteString("[]\n")
+		}
+	}
+	writeBlock(&b, value, "")
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// decodeValue reads a JSON value into fields, slices and scalars
+func decodeValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := []field{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, field{key: key.(string), value: value})
+		}
+		_, err = decoder.Token()
+		return object, err
+	case json.Delim('['):
+		array := []any{}
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// writeBlock writes a value on lines of its own at an indentation
+func writeBlock(b *bytes.Buffer, value any, indent string) {
+	switch v := value.(type) {
+	case []field:
+		for _, f := range v {
+			b.WriteString(indent + yamlString(f.key) + ":")
+			writeInline(b, f.value, indent)
+		}
+	case []any:
+		for _, item := range v {
+			// a mapping starts on the line of its dash
+			if object, ok := item.([]field); ok && len(object) > 0 {
+				var lines bytes.Buffer
+				writeBlock(&lines, object, indent+"  ")
+				b.WriteString(indent + "- " + strings.TrimPrefix(lines.String(), indent+"  "))
+				continue
+			}
+			b.WriteString(indent + "-")
+			writeInline(b, item, indent)
+		}
+	default:
+		b.WriteString(indent + yamlScalar(v) + "\n")
+	}
+}
+
+// writeInline writes a value after a key or a dash
+func writeInline(b *bytes.Buffer, value any, indent string) {
+	switch v := value.(type) {
+	case []field:
+		if len(v) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+	case []any:
+		if len(v) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+	default:
+		b.WriteString(" " + yamlScalar(v) + "\n")
+		return
+	}
+	b.WriteString("\n")
+	writeBlock(b, value, indent+"  ")
+}
+
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		return yamlString(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// yamlString quotes the strings YAML would read as something else
+func yamlString(s string) string {
+	switch {
+	case s == "", strings.TrimSpace(s) != s:
+		return strconv.Quote(s)
+	case strings.ContainsAny(s, ":#{}[],&*!|>'\"%@`\n\t\\"), strings.HasPrefix(s, "-"), strings.HasPrefix(s, "?"):
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,133 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+type area struct {
+	Name    string   `json:"name"`
+	Pokemon []string `json:"pokemon"`
+}
+
+type areas struct {
+	Region string `json:"region"`
+	Areas  []area `json:"areas"`
+	Next   string `json:"next,omitempty"`
+}
+
+func (a areas) Table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, area := range a.Areas {
+		rows = append(rows, []string{area.Name, fmt.Sprint(len(area.Pokemon))})
+	}
+	return []string{"AREA", "POKEMON"}, rows
+}
+
+type greeting string
+
+func (g greeting) Text(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "hello %s!\n", string(g))
+	return err
+}
+
+var kanto = areas{
+	Region: "kanto",
+	Areas: []area{
+		{Name: "viridian-forest-area", Pokemon: []string{"pikachu", "caterpie"}},
+		{Name: "route-1, north", Pokemon: []string{}},
+	},
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		result   any
+		expected string
+		fails    bool
+	}{
+		{
+			name:     "text from a table",
+			format:   FORMAT_TEXT,
+			result:   kanto,
+			expected: "AREA                    POKEMON\nviridian-forest-area    2\nroute-1, north          0\n",
+		},
+		{
+			name:     "text",
+			format:   FORMAT_TEXT,
+			result:   greeting("trainer"),
+			expected: "hello trainer!\n",
+		},
+		{
+			name:     "json",
+			format:   FORMAT_JSON,
+			result:   area{Name: "route-2", Pokemon: []string{"rattata"}},
+			expected: "{\n  \"name\": \"route-2\",\n  \"pokemon\": [\n    \"rattata\"\n  ]\n}\n",
+		},
+		{
+			name:     "csv",
+			format:   FORMAT_CSV,
+			result:   kanto,
+			expected: "AREA,POKEMON\nviridian-forest-area,2\n\"route-1, north\",0\n",
+		},
+		{
+			name:   "yaml",
+			format: FORMAT_YAML,
+			result: kanto,
+			expected: `region: kanto
+areas:
+  - name: viridian-forest-area
+    pokemon:
+      - pikachu
+      - caterpie
+  - name: "route-1, north"
+    pokemon: []
+`,
+		},
+		{
+			name:     "yaml scalars",
+			format:   FORMAT_YAML,
+			result:   map[string]any{"level": 5, "shiny": true, "nature": "no", "id": "42", "caught": "2026-01-01T10:00:00Z", "nickname": ""},
+			expected: "caught: \"2026-01-01T10:00:00Z\"\nid: \"42\"\nlevel: 5\nnature: \"no\"\nnickname: \"\"\nshiny: true\n",
+		},
+		{
+			name:     "yaml list",
+			format:   FORMAT_YAML,
+			result:   []any{[]string{"a"}, []string{}},
+			expected: "-\n  - a\n- []\n",
+		},
+		{
+			name:   "csv without a table",
+			format: FORMAT_CSV,
+			result: greeting("trainer"),
+			fails:  true,
+		},
+		{
+			name:   "unknown format",
+			format: "xml",
+			result: kanto,
+			fails:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := Render(&b, c.format, c.result)
+			if c.fails {
+				if err == nil {
+					t.Errorf("Error [Render]: %s should fail", c.format)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error [Render]: %v", err)
+			}
+			if b.String() != c.expected {
+				t.Errorf("Error [Render]: got\n%s\nwant\n%s", b.String(), c.expected)
+			}
+		})
+	}
+}
@@ -3,21 +3,23 @@ package session
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 
+	"github.com/charlesaraya/pokedex-go/internal/battle"
 	"github.com/charlesaraya/pokedex-go/internal/pokedex"
 )
 
 const (
 	DATA_DIR       string = "data"
-	TEST_DIR       string = "test_data"
 	SAVE_FILE_NAME string = "pokedex.json"
 )
 
 func Save(p *pokedex.Pokedex, dirName string) error {
 	// Ensure folder exists
-	dirPath := filepath.Join(".", dirName)
+	dirPath := filepath.Clean(dirName)
 	err := os.MkdirAll(dirPath, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("error: creating folder %w", err)
@@ -40,14 +42,40 @@ func Save(p *pokedex.Pokedex, dirName string) error {
 }
 
 func Load(dirName string) (*pokedex.Pokedex, error) {
-	var pokedex *pokedex.Pokedex
-	filePath := filepath.Join(".", dirName, SAVE_FILE_NAME)
+	var p *pokedex.Pokedex
+	filePath := filepath.Join(dirName, SAVE_FILE_NAME)
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("error reading file %w", err)
 	}
-	if err := json.Unmarshal(data, &pokedex); err != nil {
+	if err := json.Unmarshal(data, &p); err != nil {
 		return nil, fmt.Errorf("error: unmarshal operation failed: %w", err)
 	}
-	return pokedex, nil
+	// saves from older versions have no bag
+	if p.Bag == nil {
+		p.Bag = pokedex.NewBag()
+	}
+	// saves from older versions only know species: give each of them a Pokémon
+	if p.Pokemons == nil {
+		p.Pokemons = make(map[int]*pokedex.Individual)
+		// before seen tracking every entry was a catch
+		names := make([]string, 0, len(p.PokedexEntries))
+		for name := range p.PokedexEntries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			entry := p.PokedexEntries[name]
+			individual := pokedex.NewIndividual(entry.Pokemon, pokedex.DEFAULT_LEVEL, -1, rand.Intn)
+			// the gender rate of the species isn't saved, so its gender is rolled the
+			// first time it is needed
+			individual.Gender = ""
+			individual.HP = battle.MaxHP(entry.Pokemon, individual)
+			individual.CaughtAt = entry.CatchedAt
+			p.AddIndividual(individual)
+		}
+	}
+	// saves from older versions have no party nor PC boxes
+	p.Organize()
+	return p, nil
 }
@@ -1,26 +1,20 @@
 package session
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
-	"github.com/charlesaraya/pokedex-go/internal/api"
 	"github.com/charlesaraya/pokedex-go/internal/pokedex"
 )
 
 func TestSaveGame(t *testing.T) {
-	pokedex := pokedex.NewPokedex()
-	pikachu, err := api.GetPokemon(api.ENDPOINT_POKEMON + "pikachu")
-	if err != nil {
-		fmt.Printf("error: GetPokemon failed.")
-	}
-	pokedex.Add(pikachu)
-	dirPath := filepath.Join(".", TEST_DIR)
+	dex := pokedex.NewPokedex()
+	dex.Add(pokedex.Pokemon{Name: "pikachu"})
+	dirPath := t.TempDir()
 
 	t.Run("save pokedex", func(t *testing.T) {
-		if err := Save(pokedex, TEST_DIR); err != nil {
+		if err := Save(dex, dirPath); err != nil {
 			t.Errorf("error saving pokedex")
 		}
 		filePath := filepath.Join(dirPath, SAVE_FILE_NAME)
@@ -31,21 +25,42 @@ func TestSaveGame(t *testing.T) {
 	})
 
 	t.Run("load pokedex", func(t *testing.T) {
-		got, err := Load(TEST_DIR)
+		got, err := Load(dirPath)
 		if err != nil {
-			t.Errorf("error loading test data")
+			t.Fatalf("error loading test data: %v", err)
 		}
 		pokemonNames := got.GetAll()
 		if len(pokemonNames) == 0 {
 			t.Errorf("error no pokemons in pokedex")
 		}
 		want := "pikachu"
-		pokedexEntry, ok := got.Get(want)
-		if !ok {
-			t.Errorf("error got %v, want %v", pokedexEntry.Pokemon.Name, want)
+		if _, ok := got.Get(want); !ok {
+			t.Errorf("error got %v, want %v", pokemonNames, want)
 		}
 	})
-	if err := os.RemoveAll(dirPath); err != nil {
-		t.Errorf("error removing test data dir")
+}
+
+func TestLoadOldSave(t *testing.T) {
+	dirPath := t.TempDir()
+	oldSave := `{"PokedexEntries": {"pikachu": {"Pokemon": {"name": "pikachu"}}}}`
+	if err := os.WriteFile(filepath.Join(dirPath, SAVE_FILE_NAME), []byte(oldSave), 0644); err != nil {
+		t.Fatalf("error writing old save")
+	}
+	got, err := Load(dirPath)
+	if err != nil {
+		t.Fatalf("error loading old save: %v", err)
+	}
+	if got.Bag == nil {
+		t.Errorf("error old save should get a bag")
+	}
+	lead, ok := got.Lead()
+	if !ok || lead.Species != "pikachu" {
+		t.Errorf("error old save should get a pikachu to lead")
+	}
+	if ok && lead.Gender != "" {
+		t.Errorf("error old save shouldn't guess a gender, got %s", lead.Gender)
+	}
+	if len(got.Boxes) != pokedex.BOX_COUNT {
+		t.Errorf("error old save should get %d PC boxes, got %d", pokedex.BOX_COUNT, len(got.Boxes))
 	}
 }
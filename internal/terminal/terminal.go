@@ -1,7 +1,9 @@
 package terminal
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"syscall"
@@ -11,6 +13,13 @@ import (
 
 var oldState syscall.Termios
 
+// raw tells whether EnableRawMode turned off line editing by the terminal
+var raw bool
+
+// Stdin reads the standard input line by line outside raw mode, like a script piped to
+// the Pokédex. Scripts never prompt, so a piped script is read only as commands.
+var Stdin = bufio.NewReader(os.Stdin)
+
 const (
 	KEY_UP        string = "key_up"
 	KEY_DOWN      string = "key_down"
@@ -19,6 +28,7 @@ const (
 	KEY_ENTER     string = "key_enter"
 	KEY_BACKSPACE string = "key_backspace"
 	KEY_PRINTABLE string = "key_printable"
+	KEY_TAB       string = "key_tab"
 	KEY_UNKNOWN   string = "key_unknown"
 )
 
@@ -31,12 +41,15 @@ const (
 
 // Preprocesses a string and returns lowercased words
 func CleanInput(text string) []string {
+	return SplitInput(strings.ToLower(text))
+}
+
+// SplitInput returns the words of a string as typed, for arguments where case matters
+func SplitInput(text string) []string {
 	if len(text) == 0 {
 		return []string{}
 	}
-	lowercased := strings.ToLower(text)
-	fields := strings.Fields(lowercased)
-	return fields
+	return strings.Fields(text)
 }
 
 func EnableRawMode() error {
@@ -44,7 +57,7 @@ func EnableRawMode() error {
 	fd := int(os.Stdin.Fd())
 
 	// Get current terminal settings
-	_, _, err := syscall.Syscall6(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCGETA), uintptr(unsafe.Pointer(&oldState)), 0, 0, 0)
+	_, _, err := syscall.Syscall6(syscall.SYS_IOCTL, uintptr(fd), uintptr(ioctlGetTermios), uintptr(unsafe.Pointer(&oldState)), 0, 0, 0)
 	if err != 0 {
 		return fmt.Errorf("failed to get terminal settings: %v", err)
 	}
@@ -56,17 +69,26 @@ func EnableRawMode() error {
 	newState.Lflag &^= syscall.ICANON | syscall.ECHO
 
 	// Set new terminal attributes
-	_, _, err = syscall.Syscall6(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCSETA), uintptr(unsafe.Pointer(&newState)), 0, 0, 0)
+	_, _, err = syscall.Syscall6(syscall.SYS_IOCTL, uintptr(fd), uintptr(ioctlSetTermios), uintptr(unsafe.Pointer(&newState)), 0, 0, 0)
 	if err != 0 {
 		return fmt.Errorf("failed to set terminal to raw mode: %v", err)
 	}
+	raw = true
 
 	return nil
 }
 
 func DisableRawMode() {
 	fd := int(os.Stdin.Fd())
-	syscall.Syscall6(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCSETA), uintptr(unsafe.Pointer(&oldState)), 0, 0, 0)
+	syscall.Syscall6(syscall.SYS_IOCTL, uintptr(fd), uintptr(ioctlSetTermios), uintptr(unsafe.Pointer(&oldState)), 0, 0, 0)
+	raw = false
+}
+
+// IsTerminal reports whether a file is a terminal, rather than a pipe or a regular file
+func IsTerminal(f *os.File) bool {
+	var state syscall.Termios
+	_, _, err := syscall.Syscall6(syscall.SYS_IOCTL, f.Fd(), uintptr(ioctlGetTermios), uintptr(unsafe.Pointer(&state)), 0, 0, 0)
+	return err == 0
 }
 
 func GetKey(buffer []byte) string {
@@ -95,6 +117,10 @@ func GetKey(buffer []byte) string {
 	if buffer[0] == '\n' {
 		return KEY_ENTER
 	}
+	// Tab key
+	if buffer[0] == '\t' {
+		return KEY_TAB
+	}
 	return KEY_UNKNOWN
 }
 
@@ -147,6 +173,45 @@ func MoveCursorRight(cursor *int, max int) bool {
 	return false
 }
 
+// CompleteWord completes the word before the cursor with the candidates it prefixes.
+// A single match replaces the word, several extend it to their longest common prefix.
+// It returns the matches.
+func CompleteWord(buffer *[]rune, cursor *int, candidates []string) []string {
+	start := *cursor
+	for start > 0 && (*buffer)[start-1] != ' ' {
+		start--
+	}
+	word := strings.ToLower(string((*buffer)[start:*cursor]))
+	matches := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), word) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return matches
+	}
+	completion := []rune(matches[0])
+	for _, match := range matches[1:] {
+		other := []rune(match)
+		i := 0
+		for i < len(completion) && i < len(other) && completion[i] == other[i] {
+			i++
+		}
+		completion = completion[:i]
+	}
+	if len(completion) < len([]rune(word)) {
+		return matches
+	}
+	if len(matches) == 1 {
+		completion = append(completion, ' ')
+	}
+	rest := append(completion, (*buffer)[*cursor:]...)
+	*buffer = append((*buffer)[:start], rest...)
+	*cursor = start + len(completion)
+	return matches
+}
+
 func InitCommandHistory() ([]string, int) {
 	return []string{}, 0
 }
@@ -164,8 +229,12 @@ func AddCommand(command string, history *[]string, idx *int) {
 }
 
 func RedrawLine(buffer []rune, cursor int) {
+	redrawLine(PROMPT, buffer, cursor)
+}
+
+func redrawLine(prompt string, buffer []rune, cursor int) {
 	fmt.Print("\r") // Move to beginning of line
-	fmt.Print(PROMPT)
+	fmt.Print(prompt)
 	fmt.Print(string(buffer))
 	fmt.Print("\033[K") // Clear to end of line
 	// Move cursor back if needed
@@ -175,8 +244,51 @@ func RedrawLine(buffer []rune, cursor int) {
 	}
 }
 
+// Prompt asks a question and returns the answer typed after it. Outside raw mode the
+// answer is the next line of Stdin, and an empty one once Stdin is exhausted.
+func Prompt(question string) (string, error) {
+	if !raw {
+		fmt.Print(question)
+		line, err := Stdin.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed reading from input buffer: %w", err)
+		}
+		if !IsTerminal(os.Stdin) {
+			fmt.Println()
+		}
+		return strings.TrimSpace(line), nil
+	}
+	inputBuffer, cursor := InitBuffer()
+	buf := make([]byte, 3)
+	for {
+		redrawLine(question, inputBuffer, cursor)
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("failed reading from input buffer: %w", err)
+		}
+		switch GetKey(buf[:n]) {
+		case KEY_RIGHT:
+			MoveCursorRight(&cursor, len(inputBuffer))
+		case KEY_LEFT:
+			MoveCursorLeft(&cursor)
+		case KEY_BACKSPACE:
+			DeleteFromBuffer(&inputBuffer, &cursor)
+		case KEY_PRINTABLE:
+			AddToBuffer(rune(buf[0]), &inputBuffer, &cursor)
+		case KEY_ENTER:
+			fmt.Println()
+			return strings.TrimSpace(string(inputBuffer)), nil
+		}
+	}
+}
+
 func PrettyPrint(list []string) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, MAX_COL_PAD, ' ', 0)
+	FprettyPrint(os.Stdout, list)
+}
+
+// FprettyPrint prints a list in columns to a writer
+func FprettyPrint(out io.Writer, list []string) {
+	w := tabwriter.NewWriter(out, 0, 0, MAX_COL_PAD, ' ', 0)
 
 	for i, item := range list {
 		fmt.Fprintf(w, "- %s\t", item)
@@ -187,3 +299,29 @@ func PrettyPrint(list []string) {
 	fmt.Fprintln(w)
 	w.Flush()
 }
+
+func PrintTable(header []string, rows [][]string) {
+	FprintTable(os.Stdout, header, rows)
+}
+
+// FprintTable prints rows aligned in columns to a writer, under a header if any
+func FprintTable(out io.Writer, header []string, rows [][]string) {
+	w := tabwriter.NewWriter(out, 0, 0, MAX_COL_PAD, ' ', 0)
+
+	if len(header) > 0 {
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// Bar draws a horizontal bar of a value out of total, width characters long
+func Bar(value, total, width int) string {
+	if total <= 0 {
+		return ""
+	}
+	filled := min(max(value, 0)*width/total, width)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
@@ -1,7 +1,9 @@
 package terminal
 
 import (
+	"bufio"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -81,6 +83,10 @@ func TestKeys(t *testing.T) {
 			input:    []byte{126, 0, 0}, // Printable upper bound
 			expected: KEY_PRINTABLE,
 		},
+		{
+			input:    []byte{9, 0, 0}, // Tab
+			expected: KEY_TAB,
+		},
 		{
 			input:    []byte{128, 0, 0}, // Unknown
 			expected: KEY_UNKNOWN,
@@ -173,3 +179,77 @@ func TestBuffer(t *testing.T) {
 		}
 	})
 }
+
+func TestSplitInput(t *testing.T) {
+	got := SplitInput("  rename 3  Sparky ")
+	want := []string{"rename", "3", "Sparky"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+}
+
+func TestCompleteWord(t *testing.T) {
+	candidates := []string{"inspect", "Sparky", "squirtle", "#12"}
+	cases := []struct {
+		name          string
+		buffer        string
+		cursor        int
+		wantBuffer    string
+		wantCursor    int
+		wantMatchesNb int
+	}{
+		{name: "single match", buffer: "insp", cursor: 4, wantBuffer: "inspect ", wantCursor: 8, wantMatchesNb: 1},
+		{name: "case insensitive", buffer: "inspect sp", cursor: 10, wantBuffer: "inspect Sparky ", wantCursor: 15, wantMatchesNb: 1},
+		{name: "common prefix", buffer: "inspect s", cursor: 9, wantBuffer: "inspect s", wantCursor: 9, wantMatchesNb: 2},
+		{name: "no match", buffer: "inspect x", cursor: 9, wantBuffer: "inspect x", wantCursor: 9, wantMatchesNb: 0},
+		{name: "middle of the line", buffer: "#1 x", cursor: 2, wantBuffer: "#12  x", wantCursor: 4, wantMatchesNb: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buffer, cursor := []rune(c.buffer), c.cursor
+			matches := CompleteWord(&buffer, &cursor, candidates)
+			if string(buffer) != c.wantBuffer || cursor != c.wantCursor {
+				t.Errorf("got %q at %d want %q at %d", string(buffer), cursor, c.wantBuffer, c.wantCursor)
+			}
+			if len(matches) != c.wantMatchesNb {
+				t.Errorf("got matches %q want %d of them", matches, c.wantMatchesNb)
+			}
+		})
+	}
+}
+
+func TestBar(t *testing.T) {
+	cases := []struct {
+		value, max, width int
+		expected          string
+	}{
+		{value: 50, max: 100, width: 4, expected: "██░░"},
+		{value: 0, max: 100, width: 3, expected: "░░░"},
+		{value: 300, max: 255, width: 2, expected: "██"},
+		{value: 10, max: 0, width: 2, expected: ""},
+	}
+	for _, c := range cases {
+		if got := Bar(c.value, c.max, c.width); got != c.expected {
+			t.Errorf("Error [Bar]: %d/%d got %q want %q", c.value, c.max, got, c.expected)
+		}
+	}
+}
+
+func TestPromptOutsideRawMode(t *testing.T) {
+	defer func(original *bufio.Reader) { Stdin = original }(Stdin)
+	Stdin = bufio.NewReader(strings.NewReader("  Sparky \nyes"))
+	for _, want := range []string{"Sparky", "yes", ""} {
+		got, err := Prompt("? ")
+		if err != nil {
+			t.Fatalf("Error [Prompt]: %v", err)
+		}
+		if got != want {
+			t.Errorf("Error [Prompt]: got %q want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package terminal
+
+import "syscall"
+
+// ioctl requests that get and set the attributes of a terminal
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
@@ -0,0 +1,122 @@
+package world
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+const (
+	DEFAULT_COST int = 1
+	AVOID        int = -1
+)
+
+// Costs maps an exit tag (surf, gated...) to the cost of taking an exit carrying it.
+// Untagged exits cost DEFAULT_COST, tagged exits cost their most expensive tag, and
+// exits with a tag set to AVOID are never taken.
+type Costs map[string]int
+
+func DefaultCosts() Costs {
+	return Costs{}
+}
+
+func (c Costs) exitCost(exit Exit) int {
+	cost := DEFAULT_COST
+	for _, tag := range exit.Tags {
+		tagCost, ok := c[tag]
+		if !ok {
+			continue
+		}
+		if tagCost == AVOID {
+			return AVOID
+		}
+		cost = max(cost, tagCost)
+	}
+	return cost
+}
+
+// Step is one move along a route
+type Step struct {
+	From string
+	Exit Exit
+}
+
+type Route struct {
+	Steps []Step
+	Cost  int
+}
+
+// ShortestPaths runs Dijkstra from an area and returns the cheapest route to every
+// reachable area
+func (g *Graph) ShortestPaths(from string, costs Costs) (map[string]Route, error) {
+	if _, ok := g.Areas[from]; !ok {
+		return nil, fmt.Errorf("%w: %q in %s", ErrUnmapped, from, g.Region)
+	}
+	dist := map[string]int{from: 0}
+	prev := map[string]Step{}
+	queue := &areaQueue{{name: from, cost: 0}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(queueItem)
+		if item.cost > dist[item.name] {
+			continue // stale entry
+		}
+		for _, exit := range g.Areas[item.name].Exits {
+			cost := costs.exitCost(exit)
+			if cost == AVOID {
+				continue
+			}
+			if _, ok := g.Areas[exit.To]; !ok {
+				continue
+			}
+			next := item.cost + cost
+			if d, ok := dist[exit.To]; !ok || next < d {
+				dist[exit.To] = next
+				prev[exit.To] = Step{From: item.name, Exit: exit}
+				heap.Push(queue, queueItem{name: exit.To, cost: next})
+			}
+		}
+	}
+	routes := make(map[string]Route, len(dist))
+	for name, cost := range dist {
+		steps := []Step{}
+		for at := name; at != from; at = prev[at].From {
+			steps = append([]Step{prev[at]}, steps...)
+		}
+		routes[name] = Route{Steps: steps, Cost: cost}
+	}
+	return routes, nil
+}
+
+// ShortestPath returns the cheapest route between two areas
+func (g *Graph) ShortestPath(from string, to string, costs Costs) (Route, error) {
+	if _, ok := g.Areas[to]; !ok {
+		return Route{}, fmt.Errorf("%q is not mapped in %s", to, g.Region)
+	}
+	routes, err := g.ShortestPaths(from, costs)
+	if err != nil {
+		return Route{}, err
+	}
+	route, ok := routes[to]
+	if !ok {
+		return Route{}, fmt.Errorf("no route from %s to %s", from, to)
+	}
+	return route, nil
+}
+
+type queueItem struct {
+	name string
+	cost int
+}
+
+// areaQueue is a min-heap of areas by route cost
+type areaQueue []queueItem
+
+func (q areaQueue) Len() int           { return len(q) }
+func (q areaQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q areaQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *areaQueue) Push(x any)        { *q = append(*q, x.(queueItem)) }
+func (q *areaQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,102 @@
+package world
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+)
+
+const (
+	DATA_DIR string = "data"
+)
+
+//go:embed data/*.json
+var data embed.FS
+
+// ErrUnmapped is returned for a region, or an area of it, whose connections aren't
+// shipped
+var ErrUnmapped = errors.New("not mapped")
+
+type Exit struct {
+	Direction string   `json:"direction"`
+	To        string   `json:"to"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+type Area struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Exits    []Exit `json:"exits"`
+}
+
+// Graph holds the location areas of a region and the connections between them
+type Graph struct {
+	Region string           `json:"region"`
+	Areas  map[string]*Area `json:"-"`
+}
+
+// Load reads the adjacency graph shipped for a region
+func Load(region string) (*Graph, error) {
+	var raw struct {
+		Region string `json:"region"`
+		Areas  []Area `json:"areas"`
+	}
+	content, err := data.ReadFile(path.Join(DATA_DIR, region+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: no navigation data for region %q", ErrUnmapped, region)
+	}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal navigation data: %w", err)
+	}
+	graph := &Graph{
+		Region: raw.Region,
+		Areas:  make(map[string]*Area, len(raw.Areas)),
+	}
+	for i := range raw.Areas {
+		graph.Areas[raw.Areas[i].Name] = &raw.Areas[i]
+	}
+	return graph, nil
+}
+
+// Exits returns the exits leading out of a location area
+func (g *Graph) Exits(areaName string) ([]Exit, error) {
+	area, ok := g.Areas[areaName]
+	if !ok {
+		return nil, fmt.Errorf("%w: no exits known from %q in %s", ErrUnmapped, areaName, g.Region)
+	}
+	return area.Exits, nil
+}
+
+// Move resolves a direction, or the 1-based number of an exit as listed by Exits,
+// to the location area it leads to
+func (g *Graph) Move(from string, direction string) (*Area, error) {
+	exits, err := g.Exits(from)
+	if err != nil {
+		return nil, err
+	}
+	var exit *Exit
+	if n, err := strconv.Atoi(direction); err == nil {
+		if n < 1 || n > len(exits) {
+			return nil, fmt.Errorf("there is no exit number %d", n)
+		}
+		exit = &exits[n-1]
+	} else {
+		for i := range exits {
+			if exits[i].Direction == direction {
+				exit = &exits[i]
+				break
+			}
+		}
+	}
+	if exit == nil {
+		return nil, fmt.Errorf("you can't go %s from here", direction)
+	}
+	area, ok := g.Areas[exit.To]
+	if !ok {
+		return nil, fmt.Errorf("exit leads to unknown area %q", exit.To)
+	}
+	return area, nil
+}
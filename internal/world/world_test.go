@@ -0,0 +1,148 @@
+package world
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	graph, err := Load("kanto")
+	if err != nil {
+		t.Fatalf("error loading kanto: %v", err)
+	}
+
+	t.Run("exits are consistent", func(t *testing.T) {
+		for name, area := range graph.Areas {
+			directions := map[string]bool{}
+			for _, exit := range area.Exits {
+				if directions[exit.Direction] {
+					t.Errorf("%s has more than one exit going %s", name, exit.Direction)
+				}
+				directions[exit.Direction] = true
+				to, ok := graph.Areas[exit.To]
+				if !ok {
+					t.Errorf("%s has an exit to unknown area %s", name, exit.To)
+					continue
+				}
+				back := false
+				for _, e := range to.Exits {
+					if e.To == name {
+						back = true
+					}
+				}
+				if !back {
+					t.Errorf("%s has no way back to %s", exit.To, name)
+				}
+			}
+		}
+	})
+
+	cases := []struct {
+		from      string
+		direction string
+		expected  string
+	}{
+		{
+			from:      "pallet-town-area",
+			direction: "north",
+			expected:  "kanto-route-1-area",
+		},
+		{
+			from:      "pallet-town-area",
+			direction: "1",
+			expected:  "kanto-route-1-area",
+		},
+		{
+			from:      "mt-moon-1f",
+			direction: "down",
+			expected:  "mt-moon-b1f",
+		},
+	}
+	for _, c := range cases {
+		area, err := graph.Move(c.from, c.direction)
+		if err != nil {
+			t.Errorf("error moving %s from %s: %v", c.direction, c.from, err)
+			continue
+		}
+		if area.Name != c.expected {
+			t.Errorf("got %s want %s", area.Name, c.expected)
+		}
+	}
+
+	t.Run("invalid moves", func(t *testing.T) {
+		if _, err := graph.Move("pallet-town-area", "west"); err == nil {
+			t.Errorf("expected an error moving west from pallet town")
+		}
+		if _, err := graph.Move("pallet-town-area", "9"); err == nil {
+			t.Errorf("expected an error for an exit number out of range")
+		}
+		if _, err := Load("atlantis"); !errors.Is(err, ErrUnmapped) {
+			t.Errorf("expected an unmapped error loading an unknown region, got %v", err)
+		}
+		if _, err := graph.Exits("atlantis-area"); !errors.Is(err, ErrUnmapped) {
+			t.Errorf("expected an unmapped error for an unknown area, got %v", err)
+		}
+	})
+}
+
+func TestShortestPath(t *testing.T) {
+	graph, err := Load("kanto")
+	if err != nil {
+		t.Fatalf("error loading kanto: %v", err)
+	}
+	cases := []struct {
+		name     string
+		from     string
+		to       string
+		costs    Costs
+		expected int
+	}{
+		{
+			name:     "same area",
+			from:     "pallet-town-area",
+			to:       "pallet-town-area",
+			costs:    DefaultCosts(),
+			expected: 0,
+		},
+		{
+			name:     "walk to pewter",
+			from:     "pallet-town-area",
+			to:       "pewter-city-area",
+			costs:    DefaultCosts(),
+			expected: 6,
+		},
+		{
+			name:     "surf to cinnabar",
+			from:     "pallet-town-area",
+			to:       "cinnabar-island-area",
+			costs:    DefaultCosts(),
+			expected: 2,
+		},
+		{
+			name:     "expensive surf",
+			from:     "pallet-town-area",
+			to:       "kanto-route-1-area",
+			costs:    Costs{"surf": 10},
+			expected: 1,
+		},
+	}
+	for _, c := range cases {
+		route, err := graph.ShortestPath(c.from, c.to, c.costs)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if len(route.Steps) != c.expected || route.Cost != c.expected {
+			t.Errorf("%s: got %d steps (cost %d) want %d", c.name, len(route.Steps), route.Cost, c.expected)
+		}
+		if len(route.Steps) > 0 && route.Steps[len(route.Steps)-1].Exit.To != c.to {
+			t.Errorf("%s: route ends in %s", c.name, route.Steps[len(route.Steps)-1].Exit.To)
+		}
+	}
+
+	t.Run("avoid surf", func(t *testing.T) {
+		if _, err := graph.ShortestPath("pallet-town-area", "cinnabar-island-area", Costs{"surf": AVOID}); err == nil {
+			t.Errorf("expected no route to cinnabar without surfing")
+		}
+	})
+}